@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic 先写入同目录下的临时文件并 fsync，再重命名为目标文件，
+// 写入过程中崩溃时目标文件保持原样，不会留下只写了一半的文件
+func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	if dryRunSkip(msg("dryrun.write", path)) {
+		return nil
+	}
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	// 保留已有文件的权限
+	if info, statErr := os.Stat(path); statErr == nil {
+		perm = info.Mode().Perm()
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf(msg("file.rename_failed"), path, err)
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir 把目录项的修改刷新到磁盘，使重命名在崩溃后仍然有效；
+// 部分平台（例如 Windows）不支持对目录 fsync，此时忽略错误
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bumpResult ghc bump 的结果
+type bumpResult struct {
+	Package  string `json:"package,omitempty" yaml:"package,omitempty"`
+	Previous string `json:"previous" yaml:"previous"`
+	Version  string `json:"version" yaml:"version"`
+	Tag      string `json:"tag" yaml:"tag"`
+	Since    string `json:"since,omitempty" yaml:"since,omitempty"` // 作为基准的标签
+	Commits  int    `json:"commits" yaml:"commits"`                 // 此后修改了项目或包的提交数
+	Changed  bool   `json:"changed" yaml:"changed"`
+}
+
+// handleBump 按 major、minor 或 patch 升级配置中的版本号，以最近的标签为基准，没有标签时以配置中的版本为基准
+// 选中包时只考虑包的标签和修改了包目录的提交，自上一个标签以来没有改动时不升级
+func handleBump(part string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return configError(msg("common.load_config_failed"), err)
+	}
+	gitOps, err := NewGitOperations(projectDir)
+	if err != nil {
+		return gitError(msg("common.git_init_failed"), err)
+	}
+
+	result := &bumpResult{Package: activePackage, Previous: config.Version}
+	base := config.Version
+	if tag, since, err := gitOps.DescribeHead(config.TagPrefix, packageDir(config)); err == nil {
+		result.Since, result.Commits = tag, since
+		base = strings.TrimPrefix(tag, config.TagPrefix)
+	}
+	if result.Since != "" && result.Commits == 0 {
+		result.Version, result.Tag = config.Version, releaseTagName(config, config.Version)
+		return emitResult(result, func() {
+			fmt.Println(msg("bump.unchanged", result.Since, config.Version))
+		})
+	}
+
+	if base == "" {
+		base = "0.0.0"
+	}
+	current, err := parseSemVersion(base)
+	if err != nil {
+		return configError("%w", err)
+	}
+	next, err := current.bump(part)
+	if err != nil {
+		return usageError("%w", err)
+	}
+
+	config.Version = next.String()
+	if err := SaveConfig(config); err != nil {
+		return configError("%w", err)
+	}
+	result.Version, result.Tag, result.Changed = config.Version, releaseTagName(config, config.Version), true
+	return emitResult(result, func() {
+		fmt.Println(msg("bump.done", result.Version, result.Tag))
+		if result.Since != "" {
+			fmt.Println(msg("bump.commits", result.Commits, result.Since))
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import "fmt"
+
+// changelogResult ghc changelog 的结果
+type changelogResult struct {
+	Package string       `json:"package,omitempty" yaml:"package,omitempty"`
+	Since   string       `json:"since,omitempty" yaml:"since,omitempty"` // 起始标签，为空时包含所有提交
+	Commits []CommitInfo `json:"commits" yaml:"commits"`
+}
+
+// handleChangelog 列出自 since 标签（默认为最近的标签）以来的提交，选中包时只列出修改了包目录的提交
+func handleChangelog(since string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return configError(msg("common.load_config_failed"), err)
+	}
+	gitOps, err := NewGitOperations(projectDir)
+	if err != nil {
+		return gitError(msg("common.git_init_failed"), err)
+	}
+
+	if since == "" {
+		since, _, _ = gitOps.DescribeHead(config.TagPrefix, "")
+	}
+	commits, err := gitOps.CommitsSince(since, packageDir(config))
+	if err != nil {
+		return gitError("%w", err)
+	}
+
+	result := &changelogResult{Package: activePackage, Since: since, Commits: commits}
+	return emitResult(result, func() {
+		if since != "" {
+			fmt.Println(msg("changelog.since", since))
+		} else {
+			fmt.Println(msg("changelog.all"))
+		}
+		if len(commits) == 0 {
+			fmt.Println(msg("changelog.none"))
+			return
+		}
+		for _, commit := range commits {
+			fmt.Printf("- %s (%s)\n", commit.Subject, commit.Hash[:7])
+		}
+	})
+}
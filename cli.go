@@ -0,0 +1,481 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// 全局参数，所有命令都可以使用
+var (
+	verbose    bool   // --verbose 输出更多执行细节
+	dryRun     bool   // --dry-run 只输出将要执行的操作，不修改仓库和文件
+	configPath string // --config 指定的项目配置文件
+	workDir    string // -C/--dir 运行命令前切换到的目录
+)
+
+// 运行命令前确定的目录
+var (
+	invocationDir string // 处理 -C/--dir 之后的当前目录，命令行中的相对路径相对于该目录
+	projectDir    string // 项目根目录，运行命令时的当前目录
+)
+
+// cliCommand 命令树中的一个命令，叶子命令有 Run，命令组只有 Subcommands
+type cliCommand struct {
+	Name    string
+	Aliases []string
+	Args    string // 位置参数说明，例如 <version>
+	Short   string // 消息目录中的简短说明
+	Long    string // 消息目录中的补充说明，只在命令帮助中显示
+	Hidden  bool   // 不在帮助和补全中显示
+
+	MinArgs int
+	MaxArgs int  // -1 表示不限
+	RawArgs bool // 不解析参数，原样传给 Run
+
+	Flags    func(fs *flag.FlagSet)             // 注册命令自己的参数
+	Mutates  func() bool                        // 返回 true 时运行前获取仓库锁
+	Complete func(positional []string) []string // 位置参数的补全候选
+	Run      func(ctx context.Context, args []string) error
+
+	Subcommands []*cliCommand
+	parent      *cliCommand
+}
+
+// always 用作总是修改仓库的命令的 Mutates
+func always() bool { return true }
+
+// add 添加子命令
+func (c *cliCommand) add(subs ...*cliCommand) *cliCommand {
+	for _, sub := range subs {
+		sub.parent = c
+		c.Subcommands = append(c.Subcommands, sub)
+	}
+	return c
+}
+
+// find 按名称或别名查找子命令
+func (c *cliCommand) find(name string) *cliCommand {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+		for _, alias := range sub.Aliases {
+			if alias == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+// path 返回命令的完整名称，例如 ghc tag checkout
+func (c *cliCommand) path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.path() + " " + c.Name
+}
+
+// flagSet 生成命令的参数集合，包含全局参数；说明文字在生成时按当前语言读取
+func (c *cliCommand) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if c.Flags != nil {
+		c.Flags(fs)
+	}
+	addGlobalFlags(fs)
+	return fs
+}
+
+// localFlags 只包含命令自己的参数，用于生成帮助
+func (c *cliCommand) localFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	if c.Flags != nil {
+		c.Flags(fs)
+	}
+	return fs
+}
+
+// execute 解析参数并执行命令。命令组先解析到第一个位置参数为止，再交给子命令；
+// 叶子命令的参数和位置参数可以交替出现
+func (c *cliCommand) execute(ctx context.Context, args []string) error {
+	if c.RawArgs {
+		if err := prepareRun(); err != nil {
+			return err
+		}
+		return c.Run(ctx, args)
+	}
+
+	fs := c.flagSet()
+	rest, err := parseFlags(fs, args, len(c.Subcommands) == 0)
+	if err == nil && len(c.Subcommands) > 0 {
+		if len(rest) > 0 {
+			if sub := c.find(rest[0]); sub != nil {
+				return sub.execute(ctx, rest[1:])
+			}
+		}
+		// 既有子命令又能直接运行的命令（例如 ghc tag <version>），继续解析剩余的参数
+		if c.Run != nil {
+			rest, err = parseFlags(fs, rest, true)
+		}
+	}
+	if err := prepareRun(); err != nil {
+		return err
+	}
+
+	switch {
+	case err == flag.ErrHelp:
+		printCommandHelp(c)
+		return nil
+	case err != nil:
+		return c.usageError("%s", err)
+	case c.Run == nil && len(rest) == 0:
+		printCommandHelp(c)
+		return nil
+	case c.Run == nil:
+		if !machineOutput() {
+			fmt.Println(msg("cli.help_hint", c.path()))
+		}
+		return usageError(msg("cli.unknown_command"), strings.TrimPrefix(c.path()+" "+rest[0], "ghc "))
+	case len(rest) < c.MinArgs || (c.MaxArgs >= 0 && len(rest) > c.MaxArgs):
+		return c.usageError("%s", msg("cli.wrong_args"))
+	}
+
+	debugf(msg("verbose.command"), c.path(), rest)
+	if c.Mutates != nil && c.Mutates() && !dryRun {
+		lock, err := acquireRunLock(ctx, strings.TrimPrefix(c.path(), "ghc "), lockOptions)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+	}
+	return c.Run(ctx, rest)
+}
+
+// usageError 输出命令的使用方法并返回参数错误
+func (c *cliCommand) usageError(format string, args ...interface{}) error {
+	if !machineOutput() {
+		fmt.Println(msg("cli.usage_line", c.usage()))
+		fmt.Println(msg("cli.help_hint", c.path()))
+	}
+	return usageError("%s: %s", c.path(), fmt.Sprintf(format, args...))
+}
+
+// usage 返回命令的使用方法，例如 ghc tag checkout <version> [flags]
+func (c *cliCommand) usage() string {
+	parts := []string{c.path()}
+	if len(c.Subcommands) > 0 && c.Run == nil {
+		parts = append(parts, "<command>")
+	}
+	if c.Args != "" {
+		parts = append(parts, c.Args)
+	}
+	return strings.Join(append(parts, "[flags]"), " ")
+}
+
+// parseFlags 解析参数，返回位置参数。interspersed 为 true 时参数可以出现在位置参数之后，
+// 否则在第一个位置参数处停止；-- 之后的内容全部作为位置参数
+func parseFlags(fs *flag.FlagSet, args []string, interspersed bool) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		consumed := len(args) - fs.NArg()
+		terminated := consumed > 0 && args[consumed-1] == "--"
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if !interspersed || terminated {
+			return append(positional, args...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// prepared 是否已经完成运行前的准备
+var prepared bool
+
+// prepareRun 在运行命令或输出帮助前切换到 -C/--dir 指定的目录，再向上切换到项目根目录，
+// 之后根据项目中的配置选择语言
+func prepareRun() error {
+	if prepared {
+		return nil
+	}
+	prepared = true
+	if workDir != "" {
+		if err := os.Chdir(workDir); err != nil {
+			return usageError(msg("cli.chdir_failed"), workDir, err)
+		}
+		debugf(msg("verbose.chdir"), workDir)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf(msg("common.getwd_failed"), err)
+	}
+	invocationDir = cwd
+	configPath = resolveUserPath(configPath)
+
+	projectDir = findProjectRoot(cwd)
+	if projectDir != cwd {
+		if err := os.Chdir(projectDir); err != nil {
+			return usageError(msg("cli.chdir_failed"), projectDir, err)
+		}
+	}
+	debugf(msg("verbose.project_root"), projectDir)
+
+	setupLocale()
+	return nil
+}
+
+// findProjectRoot 从 dir 向上查找最近的包含项目配置或 .git 的目录，都没有找到时返回 dir
+func findProjectRoot(dir string) string {
+	current := dir
+	for {
+		if isProjectRoot(current) {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// isProjectRoot 判断目录中是否有项目配置或 .git，package.json 只在包含 ghc 字段时算作项目配置
+func isProjectRoot(dir string) bool {
+	for _, name := range projectConfigFiles {
+		path := filepath.Join(dir, name)
+		if fileExists(path) && (name != ManifestFile || manifestHasSection(path)) {
+			return true
+		}
+	}
+	return fileExists(filepath.Join(dir, ".git"))
+}
+
+// resolveUserPath 把命令行中的相对路径解释为相对于调用目录，而不是项目根目录
+func resolveUserPath(path string) string {
+	if path == "" || filepath.IsAbs(path) || invocationDir == "" {
+		return path
+	}
+	return filepath.Join(invocationDir, path)
+}
+
+// addGlobalFlags 注册全局参数。已经解析过的值作为默认值，避免子命令重新注册时把它们清空
+func addGlobalFlags(fs *flag.FlagSet) {
+	fs.Var(overridesFlag{}, "set", msg("flag.set"))
+	fs.StringVar(&activeProfile, "profile", activeProfile, msg("flag.profile"))
+	fs.BoolVar(&lockOptions.Wait, "wait", lockOptions.Wait, msg("flag.wait"))
+	fs.Var(timeoutFlag{}, "timeout", msg("flag.timeout"))
+	fs.Var(outputFlag{}, "output", msg("flag.output"))
+	fs.Var(langFlag{}, "lang", msg("flag.lang"))
+	fs.BoolVar(&verbose, "verbose", verbose, msg("flag.verbose"))
+	fs.BoolVar(&dryRun, "dry-run", dryRun, msg("flag.dry_run"))
+	fs.StringVar(&configPath, "config", configPath, msg("flag.config"))
+	fs.StringVar(&workDir, "dir", workDir, msg("flag.dir"))
+	fs.StringVar(&workDir, "C", workDir, msg("flag.dir_short"))
+}
+
+// overridesFlag --set key=value，可以重复使用
+type overridesFlag struct{}
+
+func (overridesFlag) String() string { return "" }
+
+func (overridesFlag) Set(value string) error {
+	key, raw, ok := strings.Cut(value, "=")
+	if !ok {
+		return errors.New(msg("flag.set_invalid"))
+	}
+	configOverrides = append(configOverrides, value)
+	if strings.TrimSpace(key) == "lang" {
+		if err := setLocale(raw); err != nil {
+			return err
+		}
+		langExplicit = true
+	}
+	return nil
+}
+
+// timeoutFlag --timeout 30s，指定后同时启用 --wait
+type timeoutFlag struct{}
+
+func (timeoutFlag) String() string { return "" }
+
+func (timeoutFlag) Set(value string) error {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return errors.New(msg("flag.timeout_invalid", value))
+	}
+	lockOptions.Wait = true
+	lockOptions.Timeout = timeout
+	return nil
+}
+
+// outputFlag --output text|json|yaml
+type outputFlag struct{}
+
+func (outputFlag) String() string { return "" }
+
+func (outputFlag) Set(value string) error { return setOutputFormat(value) }
+
+// langFlag --lang zh-CN|en
+type langFlag struct{}
+
+func (langFlag) String() string { return "" }
+
+func (langFlag) Set(value string) error {
+	if err := setLocale(value); err != nil {
+		return err
+	}
+	langExplicit = true
+	return nil
+}
+
+// debugf 使用 --verbose 时输出执行细节
+func debugf(format string, args ...interface{}) {
+	if verbose {
+		fmt.Printf("[verbose] "+format+"\n", args...)
+	}
+}
+
+// dryRunSkip 使用 --dry-run 时输出将要执行的操作并返回 true，调用方跳过该操作
+func dryRunSkip(action string) bool {
+	if !dryRun {
+		return false
+	}
+	fmt.Println(msg("cli.dry_run", action))
+	return true
+}
+
+// printCommandHelp 根据命令树生成帮助
+func printCommandHelp(c *cliCommand) {
+	if c.parent == nil {
+		fmt.Println(msg("help.title"))
+	} else {
+		fmt.Printf("%s - %s\n", c.path(), msg(c.Short))
+	}
+	if c.Long != "" {
+		fmt.Println()
+		fmt.Println(msg(c.Long))
+	}
+
+	fmt.Println()
+	fmt.Println(msg("help.usage"))
+	if c.Run != nil || len(c.Subcommands) == 0 {
+		args := c.Args
+		if args != "" {
+			args = " " + args
+		}
+		fmt.Printf("  %s%s [flags]\n", c.path(), args)
+	}
+	if len(c.Subcommands) > 0 {
+		fmt.Printf("  %s <command> [flags]\n", c.path())
+	}
+
+	if len(c.Subcommands) > 0 {
+		fmt.Println()
+		fmt.Println(msg("help.commands"))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, sub := range c.Subcommands {
+			if sub.Hidden {
+				continue
+			}
+			name := sub.Name
+			if len(sub.Aliases) > 0 {
+				name += ", " + strings.Join(sub.Aliases, ", ")
+			}
+			if sub.Args != "" {
+				name += " " + sub.Args
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", name, msg(sub.Short))
+		}
+		w.Flush()
+	}
+
+	if local := c.localFlags(); hasFlags(local) {
+		fmt.Println()
+		fmt.Println(msg("help.flags"))
+		printFlags(local)
+	}
+
+	fmt.Println()
+	fmt.Println(msg("help.global_flags"))
+	global := flag.NewFlagSet("ghc", flag.ContinueOnError)
+	addGlobalFlags(global)
+	printFlags(global)
+	fmt.Printf("  %-27s %s\n", "-h, --help", msg("flag.help"))
+
+	if c.parent == nil {
+		fmt.Println()
+		fmt.Println(msg("help.exit_codes"))
+		fmt.Println(msg("help.exit_codes_list"))
+		fmt.Println()
+		fmt.Println(msg("help.more"))
+	}
+}
+
+// hasFlags 判断参数集合是否为空
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// printFlags 按名称顺序输出参数，说明中用反引号括起的词作为参数值的占位符
+func printFlags(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		placeholder, usage := flag.UnquoteUsage(f)
+		name := "--" + f.Name
+		if len(f.Name) == 1 {
+			name = "-" + f.Name
+		}
+		if isBoolFlag(f) {
+			placeholder = ""
+		}
+		if placeholder != "" {
+			name += " " + placeholder
+		}
+		fmt.Printf("  %-27s %s\n", name, usage)
+	})
+}
+
+// isBoolFlag 判断参数是否不需要值
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// helpCommand ghc help [command...]
+func helpCommand(root *cliCommand) *cliCommand {
+	return &cliCommand{
+		Name:    "help",
+		Args:    "[command]",
+		Short:   "help.cmd.help",
+		MaxArgs: -1,
+		RawArgs: true,
+		Run: func(ctx context.Context, args []string) error {
+			c := root
+			for _, name := range args {
+				sub := c.find(name)
+				if sub == nil {
+					return usageError(msg("cli.unknown_command"), strings.Join(args, " "))
+				}
+				c = sub
+			}
+			printCommandHelp(c)
+			return nil
+		},
+	}
+}
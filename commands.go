@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -10,26 +12,25 @@ import (
 )
 
 // handleInit 处理初始化命令
-func handleInit() {
-	if fileExists(ConfigFile) {
-		fmt.Printf("项目已经初始化，配置文件 %s 已存在\n", ConfigFile)
-		return
+func handleInit() error {
+	if path := findProjectConfig(); path != "" {
+		return usageError(msg("init.already"), path)
 	}
 
 	// 创建默认配置
 	config := &Config{
-		Repo:         "",
-		Branch:       "main",
-		AutoPush:     true,
-		BuildCommand: "go build ./...",
-		Version:      "0.0.1",
-		TagPrefix:    "v",
+		SchemaVersion: CurrentSchemaVersion,
+		Repo:          "",
+		Branch:        "main",
+		AutoPush:      true,
+		BuildCommand:  "go build ./...",
+		Version:       "0.0.1",
+		TagPrefix:     "v",
 	}
 
 	err := SaveConfig(config)
 	if err != nil {
-		fmt.Printf("创建配置文件失败: %v\n", err)
-		return
+		return configError(msg("init.create_config_failed"), err)
 	}
 
 	// 创建仓库锁定文件
@@ -40,299 +41,321 @@ func handleInit() {
 
 	err = SaveRepoLock(lock)
 	if err != nil {
-		fmt.Printf("创建仓库锁定文件失败: %v\n", err)
-		return
+		return configError(msg("init.create_lock_failed"), err)
 	}
 
-	fmt.Println("项目初始化成功！")
-	fmt.Printf("已创建配置文件: %s\n", ConfigFile)
-	fmt.Printf("已创建锁定文件: %s\n", RepoLockFile)
-	fmt.Println("请使用 'ghc bind <repo-url>' 绑定仓库")
+	configFile := projectConfigFile()
+	return emitResult(&initResult{ConfigFile: configFile, LockFile: RepoLockFile}, func() {
+		fmt.Println(msg("init.success"))
+		fmt.Println(msg("init.created_config", configFile))
+		fmt.Println(msg("init.created_lock", RepoLockFile))
+		fmt.Println(msg("init.bind_hint"))
+	})
 }
 
-// handleBind 处理仓库绑定命令
-func handleBind(args []string) {
-	if len(args) == 0 {
-		fmt.Println("请提供仓库地址")
-		fmt.Println("使用方法: ghc bind <repo-url>")
-		return
-	}
+// initResult ghc init 的结果
+type initResult struct {
+	ConfigFile string `json:"config_file" yaml:"config_file"`
+	LockFile   string `json:"lock_file" yaml:"lock_file"`
+}
 
-	repoUrl := args[0]
-	if !strings.HasPrefix(repoUrl, "https://github.com/") && !strings.HasPrefix(repoUrl, "git@github.com:") {
-		fmt.Println("请提供有效的 GitHub 仓库地址")
-		return
+// bindResult ghc bind 的结果
+type bindResult struct {
+	Repo         string   `json:"repo" yaml:"repo"`
+	Forge        string   `json:"forge,omitempty" yaml:"forge,omitempty"`
+	RemotesFixed bool     `json:"remotes_fixed" yaml:"remotes_fixed"`
+	Mismatches   []string `json:"mismatches,omitempty" yaml:"mismatches,omitempty"` // 远程地址与绑定的仓库不一致的说明
+}
+
+// handleBind 处理仓库绑定命令
+// --fix-remote 会把 git 远程仓库改为配置中绑定的地址
+func handleBind(repoUrl string, fixRemote bool) error {
+	if repoUrl == "" && !fixRemote {
+		return usageError("%s", msg("bind.missing_url"))
 	}
 
 	// 加载配置文件
 	config, err := LoadConfig()
 	if err != nil {
-		fmt.Printf("加载配置失败: %v\n", err)
-		return
+		return configError(msg("common.load_config_failed"), err)
 	}
 
-	// 更新配置
-	config.Repo = repoUrl
-	err = SaveConfig(config)
-	if err != nil {
-		fmt.Printf("保存配置失败: %v\n", err)
-		return
+	var forge string
+	if repoUrl != "" {
+		parsed, err := ParseRepoURL(repoUrl)
+		if err != nil {
+			return usageError(msg("bind.invalid_url"), err)
+		}
+		if parsed.Name() == "" || (!parsed.IsLocal() && parsed.Owner() == "") {
+			return usageError(msg("bind.incomplete_url"), repoUrl)
+		}
+		forge, err = DetectForge(config, parsed)
+		if err != nil {
+			return configError(msg("bind.detect_forge_failed"), err)
+		}
 	}
 
-	// 更新锁定文件
-	lock := &RepoLock{
-		Repo:   repoUrl,
-		Branch: config.Branch,
-	}
-	err = SaveRepoLock(lock)
-	if err != nil {
-		fmt.Printf("保存仓库锁定文件失败: %v\n", err)
-		return
-	}
+	if repoUrl != "" {
+		// 更新配置，配置了 remotes 时同步修改主仓库地址
+		config.Repo = repoUrl
+		for i, remote := range config.Remotes {
+			if remote.Role == RemoteRolePrimary {
+				config.Remotes[i].URL = repoUrl
+			}
+		}
+		err = SaveConfig(config)
+		if err != nil {
+			return configError(msg("common.save_config_failed"), err)
+		}
 
-	fmt.Printf("仓库绑定成功: %s\n", repoUrl)
-}
+		// 更新锁定文件
+		lock, err := LoadRepoLock()
+		if isRepoLockCorrupt(err) {
+			return configError("%w", err)
+		}
+		if err != nil {
+			lock = &RepoLock{}
+		}
+		lock.Repo = repoUrl
+		lock.Branch = config.Branch
+		err = SaveRepoLock(lock)
+		if err != nil {
+			return configError(msg("common.save_lock_failed"), err)
+		}
 
-// handleStatus 处理状态查看命令
-func handleStatus() {
-	config, err := LoadConfig()
-	if err != nil {
-		fmt.Printf("加载配置失败: %v\n", err)
-		return
+		fmt.Println(msg("bind.success", repoUrl))
+		if forge != "" {
+			fmt.Println(msg("bind.forge", forge))
+		}
 	}
 
-	if config.Repo == "" {
-		fmt.Println("仓库未绑定，请使用 ghc bind <repo-url> 绑定仓库")
-		return
+	result := &bindResult{Repo: config.Repo, Forge: forge}
+	if !IsGitRepository(projectDir) {
+		if fixRemote {
+			return gitError("%s", msg("common.not_git_repo"))
+		}
+		return emitResult(result, nil)
+	}
+	gitOps, err := NewGitOperations(projectDir)
+	if err != nil {
+		return gitError(msg("common.git_init_failed"), err)
 	}
 
-	fmt.Printf("repo: %s\n", config.Repo)
-	fmt.Printf("branch: %s\n", config.Branch)
-	fmt.Printf("version: %s\n", config.Version)
-	fmt.Printf("tag_prefix: %s\n", config.TagPrefix)
-	fmt.Printf("auto_push: %t\n", config.AutoPush)
-	fmt.Printf("build_command: %s\n", config.BuildCommand)
-}
+	if fixRemote {
+		if err := fixRemotes(gitOps, config); err != nil {
+			return gitError(msg("bind.fix_failed"), err)
+		}
+		fmt.Println(msg("bind.fixed"))
+		result.RemotesFixed = true
+		return emitResult(result, nil)
+	}
 
-// handleTag 处理标签相关命令
-func handleTag(args []string) {
-	if len(args) == 0 {
-		fmt.Println("请提供标签操作参数")
-		fmt.Println("使用方法:")
-		fmt.Println("  ghc tag <version>           创建新标签")
-		fmt.Println("  ghc tag list                查看所有标签")
-		fmt.Println("  ghc tag checkout <version>  切换到指定版本")
-		return
-	}
-
-	subCommand := args[0]
-	switch subCommand {
-	case "list":
-		handleTagList()
-	case "checkout":
-		if len(args) < 2 {
-			fmt.Println("请提供要切换的版本号")
-			return
+	// 只提示不一致，不修改 git 配置
+	if mismatches := findRemoteMismatches(gitOps, config); len(mismatches) > 0 {
+		fmt.Println(msg("bind.mismatch"))
+		for _, mismatch := range mismatches {
+			fmt.Printf("  - %s\n", mismatch)
+			result.Mismatches = append(result.Mismatches, mismatch.String())
 		}
-		handleTagCheckout(args[1])
-	default:
-		// 默认为创建标签
-		handleTagCreate(subCommand)
+		fmt.Println(msg("bind.fix_hint"))
 	}
+	return emitResult(result, nil)
 }
 
 // handleTagCreate 创建新标签
-func handleTagCreate(version string) {
+func handleTagCreate(ctx context.Context, version string) error {
 	// 验证版本号格式
 	if version == "" {
-		fmt.Println("Error: Version cannot be empty")
-		return
+		return usageError("%s", msg("tag.empty_version"))
 	}
 
-	// 获取当前工作目录
-	cwd, err := os.Getwd()
+	// 标签按 tag_prefix 加上前缀，选中包时使用包的前缀
+	tagName, config, err := configuredTagName(version)
 	if err != nil {
-		fmt.Printf("Error getting current directory: %v\n", err)
-		return
+		return err
 	}
 
 	// 检查是否为 Git 仓库
-	if !IsGitRepository(cwd) {
-		fmt.Println("Error: Not a git repository")
-		return
+	if !IsGitRepository(projectDir) {
+		return gitError("%s", msg("common.not_git_repo"))
+	}
+
+	// 检查标签是否符合 go.mod 中的模块路径，没有配置文件时按项目根目录检查
+	if err := checkReleaseGoModule(config, tagName); err != nil {
+		return configError(msg("gomod.invalid"), err)
 	}
 
 	// 创建 Git 操作实例
-	gitOps, err := NewGitOperations(cwd)
+	gitOps, err := NewGitOperations(projectDir)
 	if err != nil {
-		fmt.Printf("Error initializing git operations: %v\n", err)
-		return
+		return gitError(msg("common.git_init_failed"), err)
 	}
 
 	// 验证仓库状态
 	if err := gitOps.ValidateRepository(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
+		return gitError(msg("tag.invalid_repo"), err)
 	}
 
 	// 创建标签
 	tagMessage := fmt.Sprintf("Release version %s", version)
-	if err := gitOps.CreateTag(version, tagMessage); err != nil {
-		fmt.Printf("Error creating tag: %v\n", err)
-		return
+	if err := gitOps.CreateTag(tagName, tagMessage); err != nil {
+		return gitError(msg("tag.create_failed"), err)
 	}
 
 	// 推送标签到远程仓库
-	if err := gitOps.PushTag(version); err != nil {
-		fmt.Printf("Error pushing tag: %v\n", err)
-		return
+	results, err := pushTagToRemotes(ctx, gitOps, tagName, nil)
+	if err != nil {
+		return networkError(msg("tag.push_failed"), err)
 	}
+	partial := countRemoteFailures(results) > 0
 
-	// 更新 .repo.lock 文件
+	// 更新 .repo.lock 文件，其中的版本是整个项目的版本，包的标签不更新
 	repoLock, err := loadRepoLock()
 	if err != nil {
-		fmt.Printf("Warning: Could not load repo lock: %v\n", err)
-	} else {
+		fmt.Println(msg("tag.load_lock_failed", err))
+	} else if activePackage == "" {
 		repoLock.CurrentVersion = version
 		repoLock.LastUpdated = time.Now().Format(time.RFC3339)
 		if err := saveRepoLock(repoLock); err != nil {
-			fmt.Printf("Warning: Could not save repo lock: %v\n", err)
+			fmt.Println(msg("tag.save_lock_failed", err))
 		}
 	}
 
-	fmt.Printf("Tag '%s' created and pushed successfully\n", version)
+	emitResult(&tagResult{Tag: tagName, Remotes: remoteOutcomes(results)}, nil)
+	if partial {
+		return partialError("%s", msg("tag.partial"))
+	}
+	fmt.Println(msg("tag.created", tagName))
+	return nil
 }
 
-// handleTagList 列出所有标签
-func handleTagList() {
-	// 获取当前工作目录
-	cwd, err := os.Getwd()
-	if err != nil {
-		fmt.Printf("Error getting current directory: %v\n", err)
-		return
-	}
+// tagResult ghc tag 创建或切换标签的结果
+type tagResult struct {
+	Tag     string          `json:"tag" yaml:"tag"`
+	Remotes []remoteOutcome `json:"remotes,omitempty" yaml:"remotes,omitempty"`
+}
 
+// tagListResult ghc tag list 的结果
+type tagListResult struct {
+	Tags   []string `json:"tags" yaml:"tags"`
+	Latest string   `json:"latest,omitempty" yaml:"latest,omitempty"`
+}
+
+// handleTagList 列出所有标签
+func handleTagList() error {
 	// 检查是否为 Git 仓库
-	if !IsGitRepository(cwd) {
-		fmt.Println("Error: Not a git repository")
-		return
+	if !IsGitRepository(projectDir) {
+		return gitError("%s", msg("common.not_git_repo"))
 	}
 
 	// 创建 Git 操作实例
-	gitOps, err := NewGitOperations(cwd)
+	gitOps, err := NewGitOperations(projectDir)
 	if err != nil {
-		fmt.Printf("Error initializing git operations: %v\n", err)
-		return
+		return gitError(msg("common.git_init_failed"), err)
 	}
 
-	// 获取标签列表
-	tags, err := gitOps.ListTags()
+	// 获取标签列表，选中包时只列出该包的标签
+	prefix, err := selectedTagPrefix()
 	if err != nil {
-		fmt.Printf("Error listing tags: %v\n", err)
-		return
+		return err
 	}
-
-	if len(tags) == 0 {
-		fmt.Println("No tags found")
-		return
-	}
-
-	fmt.Println("Available tags:")
-	for _, tag := range tags {
-		fmt.Printf("  %s\n", tag)
+	tags, err := gitOps.ListTags(prefix)
+	if err != nil {
+		return gitError(msg("tag.list_failed"), err)
 	}
 
 	// 显示当前标签
-	latestTag, err := gitOps.GetLatestTag()
-	if err == nil {
-		fmt.Printf("\nLatest tag: %s\n", latestTag)
+	latestTag, _ := gitOps.GetLatestTag(prefix)
+	if tags == nil {
+		tags = []string{}
 	}
+	return emitResult(&tagListResult{Tags: tags, Latest: latestTag}, func() {
+		if len(tags) == 0 {
+			fmt.Println(msg("tag.none"))
+			return
+		}
+
+		fmt.Println(msg("tag.available"))
+		for _, tag := range tags {
+			fmt.Printf("  %s\n", tag)
+		}
+		if latestTag != "" {
+			fmt.Println()
+			fmt.Println(msg("tag.latest", latestTag))
+		}
+	})
 }
 
 // handleTagCheckout 切换到指定版本
-func handleTagCheckout(version string) {
+func handleTagCheckout(version string) error {
 	// 验证版本号
 	if version == "" {
-		fmt.Println("Error: Version cannot be empty")
-		return
-	}
-
-	// 获取当前工作目录
-	cwd, err := os.Getwd()
-	if err != nil {
-		fmt.Printf("Error getting current directory: %v\n", err)
-		return
+		return usageError("%s", msg("tag.empty_version"))
 	}
 
 	// 检查是否为 Git 仓库
-	if !IsGitRepository(cwd) {
-		fmt.Println("Error: Not a git repository")
-		return
+	if !IsGitRepository(projectDir) {
+		return gitError("%s", msg("common.not_git_repo"))
 	}
 
 	// 创建 Git 操作实例
-	gitOps, err := NewGitOperations(cwd)
+	gitOps, err := NewGitOperations(projectDir)
 	if err != nil {
-		fmt.Printf("Error initializing git operations: %v\n", err)
-		return
+		return gitError(msg("common.git_init_failed"), err)
 	}
 
 	// 验证仓库状态
 	if err := gitOps.ValidateRepository(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
+		return gitError(msg("tag.invalid_repo"), err)
+	}
+
+	// 与 ghc tag 一样按 tag_prefix 加上前缀，带前缀的标签不存在时按原样查找，兼容以前创建的标签
+	tagName, _, err := configuredTagName(version)
+	if err != nil {
+		return err
+	}
+	if tagName != version && !gitOps.TagExists(tagName) && gitOps.TagExists(version) {
+		tagName = version
 	}
 
 	// 切换到指定标签
-	if err := gitOps.CheckoutTag(version); err != nil {
-		fmt.Printf("Error checking out tag: %v\n", err)
-		return
+	if err := gitOps.CheckoutTag(tagName); err != nil {
+		return gitError(msg("tag.checkout_failed"), err)
 	}
 
 	// 更新 .repo.lock 文件
 	repoLock, err := loadRepoLock()
 	if err != nil {
-		fmt.Printf("Warning: Could not load repo lock: %v\n", err)
+		fmt.Println(msg("tag.load_lock_failed", err))
 	} else {
 		repoLock.CurrentVersion = version
 		repoLock.LastUpdated = time.Now().Format(time.RFC3339)
 		if err := saveRepoLock(repoLock); err != nil {
-			fmt.Printf("Warning: Could not save repo lock: %v\n", err)
+			fmt.Println(msg("tag.save_lock_failed", err))
 		}
 	}
 
-	fmt.Printf("Successfully checked out tag '%s'\n", version)
+	return emitResult(&tagResult{Tag: tagName}, func() {
+		fmt.Println(msg("tag.checked_out", tagName))
+	})
 }
 
 // handlePublish 处理发布命令
-func handlePublish(args []string) {
-	// 检查是否请求帮助
-	if len(args) > 0 && (args[0] == "--help" || args[0] == "-h" || args[0] == "help") {
-		fmt.Println("ghc publish/release - 发布项目到 GitHub")
-		fmt.Println("")
-		fmt.Println("使用方法:")
-		fmt.Println("  ghc publish [version]    发布项目到 GitHub")
-		fmt.Println("  ghc release [version]    发布项目到 GitHub (同 publish)")
-		fmt.Println("")
-		fmt.Println("参数:")
-		fmt.Println("  version                  发布版本号 (可选，默认使用配置文件中的版本)")
-		fmt.Println("")
-		fmt.Println("示例:")
-		fmt.Println("  ghc publish v1.0.0       发布版本 v1.0.0")
-		fmt.Println("  ghc release              使用配置文件中的版本发布")
-		return
-	}
-
-	// 获取版本号参数
-	var version string
-	if len(args) > 0 {
-		version = args[0]
-	} else {
-		// 如果没有提供版本号，尝试从配置文件获取
-		config, err := LoadConfig()
-		if err != nil {
-			fmt.Printf("加载配置失败: %v\n", err)
-			return
+// ctx 被取消（例如按下 Ctrl-C）时在当前步骤结束后停止，不会留下未推送的标签
+func handlePublish(ctx context.Context, version string) error {
+	config, configErr := LoadConfig()
+	if configErr == nil && activeProfile != "" {
+		fmt.Println(msg("publish.profile", activeProfile))
+	}
+	if configErr == nil && activePackage != "" {
+		fmt.Println(msg("publish.package", activePackage, packageDir(config)))
+		warnUnchangedPackage(config)
+	}
+
+	// 没有提供版本号时从配置文件获取
+	if version == "" {
+		if configErr != nil {
+			return configError(msg("common.load_config_failed"), configErr)
 		}
 		version = config.Version
 		if version == "" {
@@ -340,266 +363,473 @@ func handlePublish(args []string) {
 		}
 	}
 
-	fmt.Printf("开始发布项目，版本: %s\n", version)
+	// 检查标签是否符合 go.mod 中的模块路径
+	if configErr == nil {
+		if err := checkReleaseGoModule(config, releaseTagName(config, version)); err != nil {
+			return configError(msg("gomod.invalid"), err)
+		}
+	}
 
-	// 1. 编译项目
-	fmt.Println("步骤 1/6: 编译项目...")
-	if err := buildProject(); err != nil {
-		fmt.Printf("编译失败: %v\n", err)
-		return
+	// 启用了托管平台发布时增加一个步骤
+	totalSteps := 6
+	if configErr == nil && config.Release.Enabled {
+		totalSteps = 7
 	}
-	fmt.Println("✓ 编译成功")
 
-	// 2. 获取当前工作目录
-	cwd, err := os.Getwd()
+	fmt.Println(msg("publish.start", version))
+
+	// 记录本次发布的命令日志
+	logsConfig := LogsConfig{}
+	if configErr == nil {
+		logsConfig = config.Logs
+	}
+	run, err := startLogRun(logsConfig)
 	if err != nil {
-		fmt.Printf("获取当前目录失败: %v\n", err)
-		return
+		fmt.Println(msg("publish.log_failed", err))
+	} else if run != nil {
+		currentLogRun = run
+		defer func() {
+			currentLogRun = nil
+			fmt.Println(msg("publish.log_hint", run.Dir, run.ID))
+		}()
 	}
 
-	// 3. 初始化 Git 仓库（如果需要）
-	fmt.Println("步骤 2/6: 检查 Git 仓库...")
-	if !IsGitRepository(cwd) {
-		fmt.Println("初始化 Git 仓库...")
-		if err := InitRepository(cwd); err != nil {
-			fmt.Printf("初始化 Git 仓库失败: %v\n", err)
-			return
+	// 1. 编译项目
+	fmt.Println(msg("publish.step_build", 1, totalSteps))
+	if err := buildProject(ctx); err != nil {
+		return buildError(msg("publish.build_failed"), err)
+	}
+	fmt.Println(msg("publish.build_ok"))
+
+	// 2. 初始化 Git 仓库（如果需要）
+	fmt.Println(msg("publish.step_repo", 2, totalSteps))
+	if !IsGitRepository(projectDir) {
+		fmt.Println(msg("publish.init_repo"))
+		if err := InitRepository(projectDir); err != nil {
+			return gitError(msg("publish.init_repo_failed"), err)
 		}
 	}
-	fmt.Println("✓ Git 仓库就绪")
+	fmt.Println(msg("publish.repo_ok"))
+
+	// 3. 添加远程仓库
+	if err := publishCanceled(ctx); err != nil {
+		return err
+	}
+	fmt.Println(msg("publish.step_remotes", 3, totalSteps))
+	if err := setupRemoteRepository(ctx); err != nil {
+		return configError(msg("publish.remotes_failed"), err)
+	}
+	fmt.Println(msg("publish.remotes_ok"))
+
+	// 4. 提交所有文件
+	if err := publishCanceled(ctx); err != nil {
+		return err
+	}
+	fmt.Println(msg("publish.step_commit", 4, totalSteps))
+	if err := commitAllFiles(ctx, version); err != nil {
+		return gitError(msg("publish.commit_failed"), err)
+	}
+	fmt.Println(msg("publish.commit_ok"))
 
-	// 4. 添加远程仓库
-	fmt.Println("步骤 3/6: 配置远程仓库...")
-	if err := setupRemoteRepository(); err != nil {
-		fmt.Printf("配置远程仓库失败: %v\n", err)
-		return
+	// 5. 推送到各个远程仓库
+	if err := publishCanceled(ctx); err != nil {
+		return err
 	}
-	fmt.Println("✓ 远程仓库配置完成")
+	fmt.Println(msg("publish.step_push", 5, totalSteps))
+	pushed, branchResults, err := pushToRemotes(ctx)
+	if err != nil {
+		return networkError(msg("publish.push_failed"), err)
+	}
+	fmt.Println(msg("publish.push_ok"))
 
-	// 5. 提交所有文件
-	fmt.Println("步骤 4/6: 提交文件...")
-	if err := commitAllFiles(version); err != nil {
-		fmt.Printf("提交文件失败: %v\n", err)
-		return
+	// 6. 创建发布标签，只推送到分支推送成功的远程仓库
+	if err := publishCanceled(ctx); err != nil {
+		return err
+	}
+	fmt.Println(msg("publish.step_tag", 6, totalSteps))
+	tagResults, err := createReleaseTag(ctx, version, pushed)
+	if err != nil {
+		recordRelease(version, ReleaseFailed, err.Error())
+		return networkError(msg("tag.create_failed"), err)
+	}
+	fmt.Println(msg("publish.tag_ok"))
+
+	// 7. 在托管平台上创建发布并上传附件
+	releaseURL := ""
+	if totalSteps == 7 {
+		if err := publishCanceled(ctx); err != nil {
+			record := recordRelease(version, ReleasePartial, msg("publish.canceled_before"))
+			emitResult(newPublishResult(version, record, releaseURL, branchResults, tagResults), nil)
+			return partialError(msg("publish.release_incomplete"), err)
+		}
+		fmt.Println(msg("publish.step_release", 7, totalSteps))
+		info, err := publishRelease(ctx, config, version)
+		if err != nil {
+			// 标签已经推送，发布失败时按部分发布处理
+			fmt.Println(msg("publish.release_failed", err))
+			record := recordRelease(version, ReleasePartial, err.Error())
+			emitResult(newPublishResult(version, record, releaseURL, branchResults, tagResults), nil)
+			fmt.Println()
+			return partialError(msg("publish.release_partial"), version)
+		}
+		releaseURL = info.URL
+		fmt.Println(msg("publish.release_ok", info.URL))
 	}
-	fmt.Println("✓ 文件提交完成")
 
-	// 6. 推送到 GitHub
-	fmt.Println("步骤 5/6: 推送到 GitHub...")
-	if err := pushToGitHub(); err != nil {
-		fmt.Printf("推送失败: %v\n", err)
-		return
+	if failed := countRemoteFailures(branchResults) + countRemoteFailures(tagResults); failed > 0 {
+		record := recordRelease(version, ReleasePartial, msg("publish.remote_failures", failed))
+		emitResult(newPublishResult(version, record, releaseURL, branchResults, tagResults), nil)
+		fmt.Println()
+		return partialError(msg("publish.partial"), failed, version)
 	}
-	fmt.Println("✓ 推送完成")
 
-	// 7. 创建发布标签
-	fmt.Println("步骤 6/6: 创建发布标签...")
-	if err := createReleaseTag(version); err != nil {
-		fmt.Printf("创建标签失败: %v\n", err)
-		return
+	record := recordRelease(version, ReleaseSucceeded, "")
+	return emitResult(newPublishResult(version, record, releaseURL, branchResults, tagResults), func() {
+		fmt.Println()
+		fmt.Println(msg("publish.success", version))
+	})
+}
+
+// publishResult ghc publish 的结果
+type publishResult struct {
+	Version    string           `json:"version" yaml:"version"`
+	Tag        string           `json:"tag" yaml:"tag"`
+	Commit     string           `json:"commit" yaml:"commit"`
+	Branch     string           `json:"branch" yaml:"branch"`
+	Artifacts  []ArtifactRecord `json:"artifacts" yaml:"artifacts"`
+	ReleaseURL string           `json:"release_url,omitempty" yaml:"release_url,omitempty"`
+	Outcome    string           `json:"outcome" yaml:"outcome"`
+	Detail     string           `json:"detail,omitempty" yaml:"detail,omitempty"`
+	Remotes    []remoteOutcome  `json:"remotes" yaml:"remotes"`         // 分支推送结果
+	TagRemotes []remoteOutcome  `json:"tag_remotes" yaml:"tag_remotes"` // 标签推送结果
+}
+
+// newPublishResult 根据发布记录和各远程仓库的结果生成发布结果，record 为 nil 时只包含版本号
+func newPublishResult(version string, record *ReleaseRecord, releaseURL string, branchResults, tagResults []remoteResult) *publishResult {
+	result := &publishResult{
+		Version:    version,
+		ReleaseURL: releaseURL,
+		Artifacts:  []ArtifactRecord{},
+		Remotes:    remoteOutcomes(branchResults),
+		TagRemotes: remoteOutcomes(tagResults),
+	}
+	if record != nil {
+		result.Tag, result.Commit, result.Branch = record.Tag, record.Commit, record.Branch
+		result.Outcome, result.Detail = record.Outcome, record.Detail
+		if record.Artifacts != nil {
+			result.Artifacts = record.Artifacts
+		}
 	}
-	fmt.Println("✓ 发布标签创建完成")
+	return result
+}
 
-	fmt.Printf("\n🎉 项目发布成功！版本: %s\n", version)
+// publishCanceled 发布已被取消时返回错误
+func publishCanceled(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf(msg("publish.canceled"), context.Cause(ctx))
 }
 
 // buildProject 编译项目
-func buildProject() error {
+func buildProject(ctx context.Context) error {
 	config, err := LoadConfig()
 	if err != nil {
 		// 如果没有配置文件，使用默认构建命令
-		return runCommand("go build ./...")
+		return runCommand(ctx, "go build ./...")
 	}
 
 	// 执行预编译钩子
-	if err := executePreBuildHooks(config); err != nil {
-		return fmt.Errorf("预编译失败: %v", err)
+	if err := executePreBuildHooks(ctx, config); err != nil {
+		return fmt.Errorf(msg("publish.prebuild_failed"), err)
 	}
 
-	// 执行主构建命令
-	if config.BuildCommand == "" {
-		return runCommand("go build ./...")
+	// 执行主构建命令，选中包时在包的目录中执行
+	buildCommand := config.BuildCommand
+	if buildCommand == "" {
+		buildCommand = "go build ./..."
+	}
+	if err := runCommandIn(ctx, packageDir(config), buildCommand); err != nil {
+		return err
 	}
 
-	return runCommand(config.BuildCommand)
+	// 按配置确认 Go 模块可以被解析且依赖未被篡改
+	if config.Go.Verify {
+		return verifyGoModule(ctx, packageDir(config))
+	}
+	return nil
 }
 
-// setupRemoteRepository 设置远程仓库
-func setupRemoteRepository() error {
+// setupRemoteRepository 设置远程仓库，添加 git 中缺少的已配置远程仓库
+func setupRemoteRepository(ctx context.Context) error {
 	config, err := LoadConfig()
 	if err != nil {
-		return fmt.Errorf("加载配置失败: %v", err)
+		return configError(msg("common.load_config_failed"), err)
+	}
+
+	if config.Repo == "" && len(config.Remotes) == 0 {
+		return configError("%s", msg("publish.no_repo"))
+	}
+	if err := validateRemotes(config.Remotes); err != nil {
+		return configError(msg("publish.invalid_remotes"), err)
 	}
 
-	if config.Repo == "" {
-		return fmt.Errorf("未配置远程仓库地址，请先使用 'ghc bind <repo-url>' 绑定仓库")
+	gitOps, err := NewGitOperations(projectDir)
+	if err != nil {
+		return gitError(msg("common.git_init_failed"), err)
 	}
 
-	// 检查是否已经添加了远程仓库
-	cwd, _ := os.Getwd()
-	gitOps, err := NewGitOperations(cwd)
-	if err == nil {
+	for _, remote := range config.ConfiguredRemotes() {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
 		// 如果能获取到远程 URL，说明已经配置了
-		if _, err := gitOps.GetRemoteURL(); err == nil {
-			return nil // 远程仓库已存在
+		if _, err := gitOps.GetRemoteURL(remote.Name); err == nil {
+			continue
+		}
+		fmt.Println(msg("publish.add_remote", remote.Name, remote.URL))
+		if err := gitOps.AddRemote(remote.Name, remote.URL); err != nil {
+			return err
 		}
 	}
 
-	// 添加远程仓库
-	return runCommand(fmt.Sprintf("git remote add origin %s", config.Repo))
+	// 已存在的远程仓库可能指向其他地址，推送前检查是否与绑定的仓库一致
+	return checkRemoteConsistency(gitOps, config)
 }
 
 // commitAllFiles 提交所有文件
-func commitAllFiles(version string) error {
+func commitAllFiles(ctx context.Context, version string) error {
 	// 添加所有文件
-	if err := runCommand("git add ."); err != nil {
-		return fmt.Errorf("添加文件失败: %v", err)
+	if err := runCommand(ctx, "git add ."); err != nil {
+		return fmt.Errorf(msg("publish.stage_failed"), err)
 	}
 
 	// 提交文件 - 使用 exec.Command 直接处理参数
 	commitMessage := fmt.Sprintf("Release version %s", version)
+	if activePackage != "" {
+		commitMessage = fmt.Sprintf("Release %s %s", activePackage, version)
+	}
+	if dryRunSkip(msg("dryrun.command", "git commit -m \""+commitMessage+"\"")) {
+		return nil
+	}
 	cmd := exec.Command("git", "commit", "-m", commitMessage)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	log := openCommandLog("git-commit", strings.Join(cmd.Args, " "))
+	cmd.Stdout = log.Tee(os.Stdout)
+	cmd.Stderr = log.Tee(os.Stderr)
+	err := runProcess(ctx, cmd)
+	log.Finish(err)
+	return err
 }
 
-// pushToGitHub 推送到 GitHub
-func pushToGitHub() error {
-	// 获取当前分支名
-	cwd, err := os.Getwd()
+// pushToRemotes 把当前分支推送到主仓库和所有镜像仓库
+// 返回推送成功的远程仓库和每个远程仓库的结果，全部失败时返回错误
+func pushToRemotes(ctx context.Context) ([]RemoteConfig, []remoteResult, error) {
+	config, err := LoadConfig()
 	if err != nil {
-		return fmt.Errorf("获取当前目录失败: %v", err)
+		return nil, nil, configError(msg("common.load_config_failed"), err)
 	}
 
-	gitOps, err := NewGitOperations(cwd)
+	// 获取当前分支名
+	gitOps, err := NewGitOperations(projectDir)
 	if err != nil {
-		return fmt.Errorf("初始化 Git 操作失败: %v", err)
+		return nil, nil, gitError(msg("common.git_init_failed"), err)
 	}
 
 	branch, err := gitOps.GetCurrentBranch()
 	if err != nil {
 		// 如果获取失败，使用配置文件中的分支或默认分支
-		config, configErr := LoadConfig()
-		if configErr == nil && config.Branch != "" {
+		if config.Branch != "" {
 			branch = config.Branch
 		} else {
 			branch = "main"
 		}
 	}
 
-	return runCommand(fmt.Sprintf("git push -u origin %s", branch))
+	remotes := config.PushRemotes()
+	if len(remotes) == 0 {
+		return nil, nil, configError("%s", msg("tag.no_push_remotes"))
+	}
+
+	// 发布方案中的 branch 作为推送的目标分支，例如把当前分支推送到 nightly
+	refspec := branch
+	if activeProfile != "" && config.Branch != "" && config.Branch != branch {
+		refspec = branch + ":" + config.Branch
+	}
+
+	results := forEachRemote(ctx, remotes, func(remote RemoteConfig) error {
+		// 只为主仓库设置上游分支
+		if remote.Role == RemoteRolePrimary && refspec == branch {
+			return runCommand(ctx, fmt.Sprintf("git push -u %s %s", remote.Name, refspec))
+		}
+		return runCommand(ctx, fmt.Sprintf("git push %s %s", remote.Name, refspec))
+	})
+	printRemoteResults(results)
+
+	if countRemoteFailures(results) == len(results) {
+		return nil, results, networkError("%s", msg("tag.all_pushes_failed"))
+	}
+	return succeededRemotes(remotes, results), results, nil
+}
+
+// pushTagToRemotes 把标签推送到指定的远程仓库，remotes 为空时推送到所有需要推送的远程仓库
+// 全部失败时返回错误
+func pushTagToRemotes(ctx context.Context, gitOps *GitOperations, tagName string, remotes []RemoteConfig) ([]remoteResult, error) {
+	if len(remotes) == 0 {
+		config, err := LoadConfig()
+		if err != nil {
+			return nil, configError(msg("common.load_config_failed"), err)
+		}
+		remotes = config.PushRemotes()
+	}
+	if len(remotes) == 0 {
+		return nil, configError("%s", msg("tag.no_push_remotes"))
+	}
+
+	results := forEachRemote(ctx, remotes, func(remote RemoteConfig) error {
+		return gitOps.PushTag(ctx, remote.Name, tagName)
+	})
+	printRemoteResults(results)
+
+	if countRemoteFailures(results) == len(results) {
+		return results, networkError("%s", msg("tag.all_pushes_failed"))
+	}
+	return results, nil
 }
 
-// createReleaseTag 创建发布标签
-func createReleaseTag(version string) error {
-	cwd, err := os.Getwd()
+// createReleaseTag 创建发布标签并推送到指定的远程仓库
+// 标签推送全部失败或被取消时删除本地标签，避免留下只创建了一半的发布
+func createReleaseTag(ctx context.Context, version string, remotes []RemoteConfig) ([]remoteResult, error) {
+	gitOps, err := NewGitOperations(projectDir)
 	if err != nil {
-		return fmt.Errorf("获取当前目录失败: %v", err)
+		return nil, gitError(msg("common.git_init_failed"), err)
 	}
 
-	gitOps, err := NewGitOperations(cwd)
+	config, err := LoadConfig()
 	if err != nil {
-		return fmt.Errorf("初始化 Git 操作失败: %v", err)
+		return nil, configError(msg("common.load_config_failed"), err)
 	}
 
 	// 创建标签
+	tagName := releaseTagName(config, version)
 	tagMessage := fmt.Sprintf("Release version %s", version)
-	if err := gitOps.CreateTag(version, tagMessage); err != nil {
-		return fmt.Errorf("创建标签失败: %v", err)
+	if err := gitOps.CreateTag(tagName, tagMessage); err != nil {
+		return nil, gitError(msg("tag.create_failed"), err)
 	}
 
 	// 推送标签
-	if err := gitOps.PushTag(version); err != nil {
-		return fmt.Errorf("推送标签失败: %v", err)
+	results, err := pushTagToRemotes(ctx, gitOps, tagName, remotes)
+	if err != nil {
+		if delErr := gitOps.DeleteTag(tagName); delErr != nil {
+			fmt.Println(msg("tag.delete_failed", tagName, delErr))
+		} else {
+			fmt.Println(msg("tag.deleted_unpushed", tagName))
+		}
+		return results, fmt.Errorf(msg("tag.push_failed"), err)
 	}
 
 	// 更新配置文件中的版本号
-	config, err := LoadConfig()
-	if err == nil {
-		config.Version = version
-		SaveConfig(config)
+	config.Version = version
+	if err := SaveConfig(config); err != nil {
+		return results, configError(msg("common.save_config_failed"), err)
 	}
 
-	return nil
+	return results, nil
+}
+
+// releaseTagName 为版本号加上 tag_prefix，已带有前缀的版本号保持不变
+func releaseTagName(config *Config, version string) string {
+	if config.TagPrefix == "" || strings.HasPrefix(version, config.TagPrefix) {
+		return version
+	}
+	return config.TagPrefix + version
 }
 
 // executePreBuildHooks 执行预编译钩子
-func executePreBuildHooks(config *Config) error {
+func executePreBuildHooks(ctx context.Context, config *Config) error {
 	if !config.PreBuild.Enabled {
 		return nil // 预编译未启用，直接返回
 	}
 
-	fmt.Println("🔧 执行预编译钩子...")
+	fmt.Println(msg("prebuild.start"))
 
-	// 执行预编译脚本（如果有）
-	if config.PreBuild.Script != "" {
-		fmt.Printf("执行预编译脚本: %s\n", config.PreBuild.Script)
-		if err := runCommandWithTimeout(config.PreBuild.Script, config.PreBuild.Timeout); err != nil {
-			if config.PreBuild.FailOnError {
-				return fmt.Errorf("预编译脚本执行失败: %v", err)
-			}
-			fmt.Printf("⚠️ 预编译脚本执行失败（已忽略）: %v\n", err)
-		}
+	nodes, err := buildPreBuildGraph(config.PreBuild)
+	if err != nil {
+		return fmt.Errorf(msg("prebuild.invalid"), err)
+	}
+	if len(nodes) == 0 {
+		fmt.Println(msg("prebuild.none"))
+		return nil
 	}
 
-	// 执行预编译命令列表
-	for i, command := range config.PreBuild.Commands {
-		if command == "" {
-			continue
-		}
-		fmt.Printf("执行预编译命令 [%d/%d]: %s\n", i+1, len(config.PreBuild.Commands), command)
-		if err := runCommandWithTimeout(command, config.PreBuild.Timeout); err != nil {
-			if config.PreBuild.FailOnError {
-				return fmt.Errorf("预编译命令执行失败: %v", err)
-			}
-			fmt.Printf("⚠️ 预编译命令执行失败（已忽略）: %v\n", err)
-		}
+	start := time.Now()
+	err = runPreBuildGraph(ctx, nodes, config.PreBuild)
+	printPreBuildSummary(nodes, time.Since(start), config.PreBuild.Concurrency)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("✓ 预编译钩子执行完成")
+	fmt.Println(msg("prebuild.done"))
 	return nil
 }
 
-// runCommandWithTimeout 执行带超时的系统命令
-func runCommandWithTimeout(command string, timeoutSeconds int) error {
+// runCommandWithTimeout 执行带超时的系统命令，ctx 取消时命令随之终止
+func runCommandWithTimeout(ctx context.Context, command string, timeoutSeconds int, stdout, stderr io.Writer) error {
 	if timeoutSeconds <= 0 {
 		timeoutSeconds = 300 // 默认5分钟超时
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
 	defer cancel()
 
 	// 分割命令和参数
 	parts := strings.Fields(command)
 	if len(parts) == 0 {
-		return fmt.Errorf("空命令")
+		return errors.New(msg("command.empty"))
+	}
+	if dryRunSkip(msg("dryrun.command", command)) {
+		return nil
 	}
 
-	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
-	err := cmd.Run()
+	err := runProcess(ctx, cmd)
 	if ctx.Err() == context.DeadlineExceeded {
-		return fmt.Errorf("命令执行超时（%d秒）", timeoutSeconds)
+		return errors.New(msg("command.timeout", timeoutSeconds))
 	}
 	return err
 }
 
-// runCommand 执行系统命令
-func runCommand(command string) error {
-	fmt.Printf("执行命令: %s\n", command)
+// runCommand 执行系统命令，ctx 取消时终止命令及其子进程
+func runCommand(ctx context.Context, command string) error {
+	return runCommandIn(ctx, "", command)
+}
+
+// runCommandIn 在 dir 中执行系统命令，dir 为空时在当前目录中执行
+func runCommandIn(ctx context.Context, dir, command string) error {
+	if dir != "" {
+		fmt.Println(msg("command.run_in", dir, command))
+	} else {
+		fmt.Println(msg("command.run", command))
+	}
 
 	// 分割命令和参数
 	parts := strings.Fields(command)
 	if len(parts) == 0 {
-		return fmt.Errorf("空命令")
+		return errors.New(msg("command.empty"))
+	}
+	if dryRunSkip(msg("dryrun.command", command)) {
+		return nil
 	}
 
 	cmd := exec.Command(parts[0], parts[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Dir = dir
+	log := openCommandLog(stepNameForCommand(command), command)
+	cmd.Stdout = log.Tee(os.Stdout)
+	cmd.Stderr = log.Tee(os.Stderr)
 
-	return cmd.Run()
+	err := runProcess(ctx, cmd)
+	log.Finish(err)
+	return err
 }
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// completionScripts 各 shell 的补全脚本，候选值由隐藏命令 ghc __complete 生成
+var completionScripts = map[string]string{
+	"bash": `# bash completion for ghc, usage: source <(ghc completion bash)
+_ghc() {
+    local IFS=$'\n'
+    COMPREPLY=($(ghc __complete "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _ghc ghc
+`,
+	"zsh": `#compdef ghc
+# zsh completion for ghc, usage: source <(ghc completion zsh)
+_ghc() {
+    local -a candidates
+    candidates=("${(@f)$(ghc __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)}")
+    compadd -a candidates
+}
+compdef _ghc ghc
+`,
+	"fish": `# fish completion for ghc, usage: ghc completion fish | source
+function __ghc_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    ghc __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c ghc -f -a '(__ghc_complete)'
+`,
+}
+
+// completionCommand ghc completion bash|zsh|fish
+func completionCommand(root *cliCommand) *cliCommand {
+	completion := &cliCommand{Name: "completion", Short: "help.cmd.completion", Long: "help.long.completion"}
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		script := completionScripts[shell]
+		completion.add(&cliCommand{
+			Name: shell, Short: "help.cmd.completion_" + shell,
+			Run: func(ctx context.Context, args []string) error {
+				fmt.Fprint(resultOutput, script)
+				return nil
+			},
+		})
+	}
+	return completion
+}
+
+// completeCommand 隐藏命令 ghc __complete <words...>，最后一个参数是正在输入的词，
+// 每行输出一个候选值。补全时不获取锁，出错时不输出候选值
+func completeCommand(root *cliCommand) *cliCommand {
+	return &cliCommand{
+		Name:    "__complete",
+		Hidden:  true,
+		MaxArgs: -1,
+		RawArgs: true,
+		Run: func(ctx context.Context, args []string) error {
+			for _, candidate := range completeArgs(root, args) {
+				fmt.Fprintln(resultOutput, candidate)
+			}
+			return nil
+		},
+	}
+}
+
+// completeArgs 沿命令树解析已输入的词，返回最后一个词的候选值
+func completeArgs(root *cliCommand, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	c := root
+	fs := c.flagSet()
+	var positional []string
+	var pending *flag.Flag // 等待取值的参数
+	for _, word := range words[:len(words)-1] {
+		if pending != nil {
+			pending = nil
+			continue
+		}
+		if strings.HasPrefix(word, "-") && word != "-" && word != "--" {
+			name := strings.TrimLeft(word, "-")
+			if strings.Contains(name, "=") {
+				continue
+			}
+			if f := fs.Lookup(name); f != nil && !isBoolFlag(f) {
+				pending = f
+			}
+			continue
+		}
+		if len(positional) == 0 {
+			if sub := c.find(word); sub != nil && !sub.RawArgs {
+				c, fs = sub, sub.flagSet()
+				continue
+			}
+		}
+		positional = append(positional, word)
+	}
+
+	var candidates []string
+	switch {
+	case pending != nil:
+		candidates = completeFlagValue(pending.Name)
+	case strings.HasPrefix(current, "-"):
+		fs.VisitAll(func(f *flag.Flag) {
+			if len(f.Name) == 1 {
+				candidates = append(candidates, "-"+f.Name)
+			} else {
+				candidates = append(candidates, "--"+f.Name)
+			}
+		})
+	default:
+		if len(positional) == 0 {
+			for _, sub := range c.Subcommands {
+				if !sub.Hidden {
+					candidates = append(candidates, sub.Name)
+				}
+			}
+		}
+		if c.Complete != nil {
+			candidates = append(candidates, c.Complete(positional)...)
+		}
+	}
+
+	var matched []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matched = append(matched, candidate)
+		}
+	}
+	return matched
+}
+
+// completeFlagValue 参数值的候选值
+func completeFlagValue(name string) []string {
+	switch name {
+	case "output":
+		return []string{OutputText, OutputJSON, OutputYAML}
+	case "lang":
+		return []string{LocaleZH, LocaleEN}
+	case "profile":
+		return configuredNames("profiles")
+	case "package":
+		return configuredNames("packages")
+	case "since":
+		return completeTags(nil)
+	case "to":
+		var targets []string
+		for target := range configConvertTargets {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+		return targets
+	}
+	return nil
+}
+
+// completeTags 补全标签名称
+func completeTags(positional []string) []string {
+	if len(positional) > 0 {
+		return nil
+	}
+	gitOps, err := NewGitOperations(".")
+	if err != nil {
+		return nil
+	}
+	tags, _ := gitOps.ListTags("")
+	return tags
+}
+
+// completeLogs 补全运行记录，第二个参数补全该次运行中的步骤
+func completeLogs(positional []string) []string {
+	runs, err := listLogRuns()
+	if err != nil {
+		return nil
+	}
+	switch len(positional) {
+	case 0:
+		return append([]string{"latest"}, runs...)
+	case 1:
+		runID := positional[0]
+		if runID == "latest" && len(runs) > 0 {
+			runID = runs[len(runs)-1]
+		}
+		logs, _ := filepath.Glob(filepath.Join(LogsDir, runID, "*.log"))
+		var steps []string
+		for _, path := range logs {
+			steps = append(steps, strings.TrimSuffix(filepath.Base(path), ".log"))
+		}
+		return steps
+	}
+	return nil
+}
+
+// completeConfigKeys 补全配置项，ghc config set 的第二个参数补全配置项的可选值
+func completeConfigKeys(positional []string) []string {
+	switch len(positional) {
+	case 0:
+		var keys []string
+		for key := range schemaRules {
+			if !strings.Contains(key, "[]") && !strings.Contains(key, "*") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		return keys
+	case 1:
+		_, schema, err := parseConfigPath(positional[0])
+		if err != nil {
+			return nil
+		}
+		if schema.Type == "boolean" {
+			return []string{"true", "false"}
+		}
+		return schema.Enum
+	}
+	return nil
+}
+
+// configuredNames 读取项目配置中 profiles 或 packages 下的名称，补全时不做迁移和校验
+func configuredNames(section string) []string {
+	path := findProjectConfig()
+	if path == "" {
+		return nil
+	}
+	doc, err := loadConfigDocument(path)
+	if err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	mapping, _ := mappingValue(doc.Content[0], section)
+	if mapping == nil {
+		return nil
+	}
+	var names []string
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		names = append(names, mapping.Content[i].Value)
+	}
+	return names
+}
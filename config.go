@@ -1,38 +1,91 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
-	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"os"
+
+	"gopkg.in/yaml.v3"
 )
 
+// PreBuildStep 预编译步骤，通过 needs 声明依赖关系
+type PreBuildStep struct {
+	ID      string   `yaml:"id"`
+	Run     string   `yaml:"run"`
+	Needs   []string `yaml:"needs,omitempty"`
+	Timeout int      `yaml:"timeout,omitempty"`
+}
+
 // PreBuildConfig 预编译配置结构
 type PreBuildConfig struct {
-	Enabled     bool     `yaml:"enabled"`
-	Commands    []string `yaml:"commands"`
-	Script      string   `yaml:"script"`
-	Timeout     int      `yaml:"timeout"`
-	FailOnError bool     `yaml:"fail_on_error"`
+	Enabled     bool           `yaml:"enabled"`
+	Commands    []string       `yaml:"commands,omitempty"` // 已废弃，加载时迁移为 steps
+	Script      string         `yaml:"script,omitempty"`   // 已废弃，加载时迁移为 steps
+	Steps       []PreBuildStep `yaml:"steps,omitempty"`
+	Concurrency int            `yaml:"concurrency,omitempty"`
+	Timeout     int            `yaml:"timeout"`
+	FailOnError bool           `yaml:"fail_on_error"`
+}
+
+// ReleaseConfig 托管平台发布配置
+type ReleaseConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	Name       string   `yaml:"name,omitempty"`  // 发布标题，{version} 会被替换为版本号
+	Notes      string   `yaml:"notes,omitempty"` // 发布说明
+	Draft      bool     `yaml:"draft,omitempty"`
+	Prerelease bool     `yaml:"prerelease,omitempty"`
+	Assets     []string `yaml:"assets,omitempty"`  // 要上传的附件，支持通配符
+	APIURL     string   `yaml:"api_url,omitempty"` // 托管平台 API 地址，为空时根据仓库地址推导
+}
+
+// RemoteConfig 远程仓库配置
+type RemoteConfig struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	Role string `yaml:"role,omitempty"` // primary、mirror 或 upstream，默认为 mirror
+}
+
+// PackageConfig monorepo 中独立发布版本的包，通过 --package 选择
+type PackageConfig struct {
+	Path         string `yaml:"path"`                    // 包所在的目录，相对于项目根目录
+	TagPrefix    string `yaml:"tag_prefix,omitempty"`    // 标签前缀，例如 api/v，为空时使用 <path>/v
+	BuildCommand string `yaml:"build_command,omitempty"` // 构建命令，在包的目录中执行
+	Version      string `yaml:"version,omitempty"`
 }
 
 // Config 项目配置结构
 type Config struct {
-	Repo         string         `yaml:"repo"`
-	Branch       string         `yaml:"branch"`
-	AutoPush     bool           `yaml:"auto_push"`
-	BuildCommand string         `yaml:"build_command"`
-	Version      string         `yaml:"version"`
-	TagPrefix    string         `yaml:"tag_prefix"`
-	PreBuild     PreBuildConfig `yaml:"pre_build"`
+	SchemaVersion int                      `yaml:"schema_version"` // 配置文件版本，用于自动迁移
+	Repo          string                   `yaml:"repo"`
+	Branch        string                   `yaml:"branch"`
+	AutoPush      bool                     `yaml:"auto_push"`
+	BuildCommand  string                   `yaml:"build_command"`
+	Version       string                   `yaml:"version"`
+	TagPrefix     string                   `yaml:"tag_prefix"`
+	PreBuild      PreBuildConfig           `yaml:"pre_build"`
+	Remotes       []RemoteConfig           `yaml:"remotes,omitempty"`
+	RemoteCheck   string                   `yaml:"remote_check,omitempty"` // block 或 warn，默认为 block
+	Forge         string                   `yaml:"forge,omitempty"`        // 托管平台，为空时根据仓库地址识别
+	ForgeHosts    map[string]string        `yaml:"forge_hosts,omitempty"`  // 自建托管平台的主机名映射
+	Token         string                   `yaml:"token,omitempty"`        // 托管平台 API 令牌，也可以通过环境变量提供
+	Release       ReleaseConfig            `yaml:"release,omitempty"`
+	Logs          LogsConfig               `yaml:"logs,omitempty"`
+	Go            GoConfig                 `yaml:"go,omitempty"`
+	Lang          string                   `yaml:"lang,omitempty"`     // 界面语言 zh-CN 或 en，为空时根据 LANG 选择
+	Profiles      map[string]Config        `yaml:"profiles,omitempty"` // 命名的发布方案，通过 --profile 选择
+	Packages      map[string]PackageConfig `yaml:"packages,omitempty"` // monorepo 中的包，通过 --package 选择
 }
 
 // RepoLock 仓库锁定文件结构
+// Checksum 写在最前面，文件被截断时校验和仍然保留，不会被当作没有校验和的旧文件
 type RepoLock struct {
-	Repo           string `yaml:"repo"`
-	Branch         string `yaml:"branch"`
-	CurrentVersion string `yaml:"current_version"`
-	LastUpdated    string `yaml:"last_updated"`
+	Checksum       string          `yaml:"checksum,omitempty"` // 其余字段的 SHA-256，用于发现损坏或被截断的文件
+	Repo           string          `yaml:"repo"`
+	Branch         string          `yaml:"branch"`
+	CurrentVersion string          `yaml:"current_version"`
+	LastUpdated    string          `yaml:"last_updated"`
+	History        []ReleaseRecord `yaml:"history,omitempty"` // 发布历史，只追加不修改
 }
 
 const (
@@ -40,71 +93,133 @@ const (
 	RepoLockFile = ".repo.lock"
 )
 
-// LoadConfig 加载配置文件
+// LoadConfig 加载合并后的配置，配置不符合模式时返回带有行列号的 ConfigErrors
+// 配置依次来自全局配置、项目配置、本地配置、GHC_* 环境变量和 --set 参数
 func LoadConfig() (*Config, error) {
-	if !fileExists(ConfigFile) {
-		return nil, fmt.Errorf("配置文件 %s 不存在，请先运行 ghc init", ConfigFile)
-	}
-
-	data, err := ioutil.ReadFile(ConfigFile)
+	layered, err := LoadLayeredConfig()
 	if err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+		return nil, err
 	}
-
-	var config Config
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %v", err)
-	}
-
-	return &config, nil
+	return layered.Config, nil
 }
 
 // SaveConfig 保存配置文件
+// 文件已存在时只把相对于合并后配置发生变化的值写入项目配置，保留注释和键的顺序，
+// 来自全局配置、本地配置和环境变量的值不会被写入
 func SaveConfig(config *Config) error {
-	data, err := yaml.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("序列化配置失败: %v", err)
+	path := findProjectConfig()
+	if path == "" {
+		data, err := marshalYAML(config)
+		if err != nil {
+			return fmt.Errorf(msg("config.encode_failed"), err)
+		}
+		if err := writeFileAtomic(projectConfigFile(), data, 0644); err != nil {
+			return fmt.Errorf(msg("config.write_failed"), err)
+		}
+		return nil
 	}
 
-	err = ioutil.WriteFile(ConfigFile, data, 0644)
+	layered, err := LoadLayeredConfig()
 	if err != nil {
-		return fmt.Errorf("保存配置文件失败: %v", err)
+		return err
+	}
+	var project *yaml.Node
+	for _, layer := range layered.Layers {
+		if layer.Path == path {
+			project = layer.Doc
+		}
 	}
 
-	return nil
+	var desired yaml.Node
+	if err := desired.Encode(config); err != nil {
+		return fmt.Errorf(msg("config.encode_failed"), err)
+	}
+	if err := applyConfigDelta(project, layered.Doc.Content[0], &desired, nil, layerRoute(layered.Origins)); err != nil {
+		return err
+	}
+
+	return saveConfigDocument(path, project)
+}
+
+// marshalYAML 以两个空格缩进序列化为 YAML
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
-// LoadRepoLock 加载仓库锁定文件
+// LoadRepoLock 加载仓库锁定文件，文件损坏时在终端中询问是否重建
 func LoadRepoLock() (*RepoLock, error) {
+	lock, err := readRepoLock()
+	if corrupt, ok := err.(*RepoLockCorruptError); ok {
+		return repairRepoLock(corrupt)
+	}
+	return lock, err
+}
+
+// readRepoLock 读取并校验仓库锁定文件，文件损坏时返回 RepoLockCorruptError
+func readRepoLock() (*RepoLock, error) {
 	if !fileExists(RepoLockFile) {
-		return nil, fmt.Errorf("仓库锁定文件 %s 不存在", RepoLockFile)
+		return nil, fmt.Errorf(msg("repolock.missing"), RepoLockFile)
 	}
 
 	data, err := ioutil.ReadFile(RepoLockFile)
 	if err != nil {
-		return nil, fmt.Errorf("读取仓库锁定文件失败: %v", err)
+		return nil, fmt.Errorf(msg("repolock.read_failed"), err)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, &RepoLockCorruptError{Reason: msg("repolock.reason_empty")}
 	}
 
 	var lock RepoLock
 	err = yaml.Unmarshal(data, &lock)
 	if err != nil {
-		return nil, fmt.Errorf("解析仓库锁定文件失败: %v", err)
+		return nil, &RepoLockCorruptError{Reason: msg("repolock.reason_parse", err)}
+	}
+
+	// 旧版本写入的锁定文件没有校验和，只有包含旧版本总会写入的全部字段时才直接使用，
+	// 否则是写入校验和之后被截断的文件
+	if lock.Checksum == "" {
+		if !isLegacyRepoLock(data) {
+			return nil, &RepoLockCorruptError{Reason: msg("repolock.reason_no_checksum")}
+		}
+		return &lock, nil
 	}
 
+	sum, err := lock.checksum()
+	if err != nil {
+		return nil, err
+	}
+	if sum != lock.Checksum {
+		return nil, &RepoLockCorruptError{Reason: msg("repolock.reason_checksum")}
+	}
 	return &lock, nil
 }
 
 // SaveRepoLock 保存仓库锁定文件
 func SaveRepoLock(lock *RepoLock) error {
-	data, err := yaml.Marshal(lock)
+	sum, err := lock.checksum()
+	if err != nil {
+		return fmt.Errorf(msg("repolock.encode_failed"), err)
+	}
+	lock.Checksum = sum
+
+	data, err := marshalYAML(lock)
 	if err != nil {
-		return fmt.Errorf("序列化仓库锁定信息失败: %v", err)
+		return fmt.Errorf(msg("repolock.encode_failed"), err)
 	}
 
-	err = ioutil.WriteFile(RepoLockFile, data, 0644)
+	err = writeFileAtomic(RepoLockFile, data, 0644)
 	if err != nil {
-		return fmt.Errorf("保存仓库锁定文件失败: %v", err)
+		return fmt.Errorf(msg("repolock.write_failed"), err)
 	}
 
 	return nil
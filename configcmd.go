@@ -0,0 +1,465 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configCommand ghc config 及其子命令，get、set、unset 可以用 --global、--local 或 --project 指定作用域
+func configCommand() *cliCommand {
+	var scope string
+	scopeFlags := func(fs *flag.FlagSet) {
+		fs.Var(configScopeFlag{&scope, "global"}, "global", msg("flag.scope_global"))
+		fs.Var(configScopeFlag{&scope, "local"}, "local", msg("flag.scope_local"))
+		fs.Var(configScopeFlag{&scope, "project"}, "project", msg("flag.scope_project"))
+	}
+	var schemaOutput, convertTo string
+	var showOrigin, convertForce, migrateCheck bool
+
+	config := &cliCommand{Name: "config", Short: "help.cmd.config"}
+	return config.add(
+		&cliCommand{
+			Name: "validate", Args: "[file]", Short: "help.cmd.config_validate", MaxArgs: 1,
+			Run: func(ctx context.Context, args []string) error { return handleConfigValidate(args) },
+		},
+		&cliCommand{
+			Name: "schema", Short: "help.cmd.config_schema",
+			Flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&schemaOutput, "o", "", msg("flag.schema_output"))
+			},
+			Run: func(ctx context.Context, args []string) error { return handleConfigSchema(schemaOutput) },
+		},
+		&cliCommand{
+			Name: "get", Args: "<key>", Short: "help.cmd.config_get", Long: "help.long.config_keys", MinArgs: 1, MaxArgs: 1,
+			Flags:    scopeFlags,
+			Complete: completeConfigKeys,
+			Run:      func(ctx context.Context, args []string) error { return handleConfigGet(scope, args[0]) },
+		},
+		&cliCommand{
+			Name: "set", Args: "<key> <value>", Short: "help.cmd.config_set", Long: "help.long.config_keys", MinArgs: 2, MaxArgs: 2,
+			Flags:    scopeFlags,
+			Mutates:  always,
+			Complete: completeConfigKeys,
+			Run:      func(ctx context.Context, args []string) error { return handleConfigSet(scope, args[0], args[1]) },
+		},
+		&cliCommand{
+			Name: "unset", Args: "<key>", Short: "help.cmd.config_unset", Long: "help.long.config_keys", MinArgs: 1, MaxArgs: 1,
+			Flags:    scopeFlags,
+			Mutates:  always,
+			Complete: completeConfigKeys,
+			Run:      func(ctx context.Context, args []string) error { return handleConfigUnset(scope, args[0]) },
+		},
+		&cliCommand{
+			Name: "list", Short: "help.cmd.config_list",
+			Flags: func(fs *flag.FlagSet) {
+				fs.BoolVar(&showOrigin, "show-origin", false, msg("flag.show_origin"))
+			},
+			Run: func(ctx context.Context, args []string) error { return handleConfigList(showOrigin) },
+		},
+		&cliCommand{
+			Name: "convert", Short: "help.cmd.config_convert",
+			Flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&convertTo, "to", "", msg("flag.convert_to"))
+				fs.BoolVar(&convertForce, "force", false, msg("flag.convert_force"))
+			},
+			Mutates: always,
+			Run: func(ctx context.Context, args []string) error {
+				return handleConfigConvert(convertTo, convertForce)
+			},
+		},
+		&cliCommand{
+			Name: "migrate", Short: "help.cmd.config_migrate",
+			Flags: func(fs *flag.FlagSet) {
+				fs.BoolVar(&migrateCheck, "check", false, msg("flag.migrate_check"))
+			},
+			Mutates: func() bool { return !migrateCheck },
+			Run:     func(ctx context.Context, args []string) error { return handleConfigMigrate(migrateCheck) },
+		},
+	)
+}
+
+// configScopeFlag --global、--local 和 --project，多个同时出现时以最后一个为准
+type configScopeFlag struct {
+	scope *string
+	value string
+}
+
+func (f configScopeFlag) String() string { return "" }
+
+func (f configScopeFlag) IsBoolFlag() bool { return true }
+
+func (f configScopeFlag) Set(value string) error {
+	if value == "true" {
+		*f.scope = f.value
+	}
+	return nil
+}
+
+// handleConfigValidate 校验配置文件，与 LoadConfig 执行相同的检查
+func handleConfigValidate(args []string) error {
+	path := projectConfigFile()
+	if len(args) > 0 {
+		path = resolveUserPath(args[0])
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return configError(msg("config.read_failed"), err)
+	}
+
+	doc, err := decodeConfigData(path, data)
+	if err != nil {
+		return configError("%w", err)
+	}
+	if errs := validateConfigDocument(path, doc); len(errs) > 0 {
+		emitResult(&configValidateResult{File: path, Errors: errs}, func() {
+			fmt.Println(errs)
+			fmt.Println()
+		})
+		return configError(msg("config.error_count"), len(errs))
+	}
+
+	return emitResult(&configValidateResult{File: path, Valid: true, Errors: ConfigErrors{}}, func() {
+		fmt.Println(msg("config.valid", path))
+	})
+}
+
+// configValidateResult ghc config validate 的结果
+type configValidateResult struct {
+	File   string       `json:"file" yaml:"file"`
+	Valid  bool         `json:"valid" yaml:"valid"`
+	Errors ConfigErrors `json:"errors" yaml:"errors"`
+}
+
+// configValueResult ghc config get/set/unset 的结果
+type configValueResult struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	File   string      `json:"file,omitempty" yaml:"file,omitempty"`     // set/unset 修改的文件
+	Origin string      `json:"origin,omitempty" yaml:"origin,omitempty"` // get 未指定作用域时值的来源
+}
+
+// configEntry ghc config list 中的一项
+type configEntry struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Origin string      `json:"origin" yaml:"origin"`
+}
+
+// nodeValue 把配置节点解码为普通的值，用于输出结果
+func nodeValue(node *yaml.Node) interface{} {
+	var value interface{}
+	node.Decode(&value)
+	return value
+}
+
+// handleConfigSchema 输出配置的 JSON Schema，-o 指定时写入文件
+func handleConfigSchema(output string) error {
+	data, err := json.MarshalIndent(ConfigJSONSchema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf(msg("config.schema_encode_failed"), err)
+	}
+	data = append(data, '\n')
+
+	if output != "" {
+		output = resolveUserPath(output)
+		if err := writeFileAtomic(output, data, 0644); err != nil {
+			return fmt.Errorf(msg("config.schema_write_failed"), err)
+		}
+		fmt.Println(msg("config.schema_written", output))
+		return nil
+	}
+
+	return emitResult(ConfigJSONSchema(), func() { os.Stdout.Write(data) })
+}
+
+// loadScopedConfigDocument 读取作用域对应的配置文件，全局和本地配置文件不存在时返回空文档
+func loadScopedConfigDocument(scope string) (string, *yaml.Node, error) {
+	path, err := configFileForScope(scope)
+	if err != nil {
+		return "", nil, err
+	}
+	if (scope == "global" || scope == "local") && !fileExists(path) {
+		return path, &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}, nil
+	}
+	doc, err := loadConfigDocument(path)
+	return path, doc, err
+}
+
+// handleConfigGet 输出配置项的值，标量直接输出，数组和对象以 YAML 输出
+// 未指定作用域时输出合并后的值
+func handleConfigGet(scope, key string) error {
+	segments, _, err := parseConfigPath(key)
+	if err != nil {
+		return usageError("%w", err)
+	}
+
+	var doc *yaml.Node
+	origin := ""
+	if scope == "" {
+		layered, err := LoadLayeredConfig()
+		if err != nil {
+			return configError("%w", err)
+		}
+		doc = layered.Doc
+		origin = layered.Origins[formatConfigPath(segments)]
+	} else if _, doc, err = loadScopedConfigDocument(scope); err != nil {
+		return configError("%w", err)
+	}
+
+	node := lookupConfigNode(doc, segments)
+	if node == nil {
+		return configError(msg("config.key_not_set"), key)
+	}
+	if machineOutput() || node.Kind == yaml.ScalarNode {
+		return emitResult(&configValueResult{Key: key, Value: nodeValue(node), Origin: origin}, func() {
+			fmt.Println(formatConfigValue(node))
+		})
+	}
+
+	data, err := encodeConfigDocument(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}})
+	if err != nil {
+		return fmt.Errorf(msg("config.encode_failed"), err)
+	}
+	os.Stdout.Write(data)
+	return nil
+}
+
+// handleConfigSet 按模式转换类型后修改配置项，修改后的配置必须通过校验
+// 默认写入项目配置，--global 写入用户配置，--local 写入 ghc.local.yaml
+func handleConfigSet(scope, key, raw string) error {
+	segments, schema, err := parseConfigPath(key)
+	if err != nil {
+		return usageError("%w", err)
+	}
+	value, err := coerceConfigValue(schema, raw)
+	if err != nil {
+		return usageError("%s: %v", key, err)
+	}
+
+	path, doc, err := loadScopedConfigDocument(scope)
+	if err != nil {
+		return configError("%w", err)
+	}
+	if err := setConfigNode(doc, segments, value); err != nil {
+		return usageError("%s: %v", key, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return configError(msg("config.mkdir_failed"), err)
+	}
+	if err := saveConfigDocument(path, doc); err != nil {
+		return configError("%w", err)
+	}
+
+	return emitResult(&configValueResult{Key: key, Value: nodeValue(value), File: path}, func() {
+		fmt.Printf("✓ %s = %s (%s)\n", key, formatConfigValue(value), path)
+	})
+}
+
+// handleConfigUnset 删除配置项，未设置的配置项视为成功
+func handleConfigUnset(scope, key string) error {
+	segments, _, err := parseConfigPath(key)
+	if err != nil {
+		return usageError("%w", err)
+	}
+	path, doc, err := loadScopedConfigDocument(scope)
+	if err != nil {
+		return configError("%w", err)
+	}
+
+	if !unsetConfigNode(doc, segments) {
+		return emitResult(&configValueResult{Key: key}, func() {
+			fmt.Println(msg("config.key_not_in_file", key, path))
+		})
+	}
+	if err := saveConfigDocument(path, doc); err != nil {
+		return configError("%w", err)
+	}
+
+	return emitResult(&configValueResult{Key: key, File: path}, func() {
+		fmt.Println(msg("config.key_removed", path, key))
+	})
+}
+
+// handleConfigList 以 key = value 的形式列出合并后的配置项，--show-origin 同时输出每一项的来源
+func handleConfigList(showOrigin bool) error {
+	layered, err := LoadLayeredConfig()
+	if err != nil {
+		return configError("%w", err)
+	}
+
+	entries := []configEntry{}
+	flattenConfigNode(layered.Doc.Content[0], "", func(path string, value *yaml.Node) {
+		entries = append(entries, configEntry{Key: path, Value: nodeValue(value), Origin: layered.Origins[path]})
+		if machineOutput() {
+			return
+		}
+		if showOrigin {
+			fmt.Printf("%-28s %s = %s\n", layered.Origins[path], path, formatConfigValue(value))
+			return
+		}
+		fmt.Printf("%s = %s\n", path, formatConfigValue(value))
+	})
+	return emitResult(entries, nil)
+}
+
+// configConvertTargets 各格式转换后的配置文件
+var configConvertTargets = map[string]string{
+	"yaml":       ConfigFile,
+	"yml":        "ghc.config.yml",
+	"toml":       "ghc.config.toml",
+	"json":       "ghc.config.json",
+	ManifestFile: ManifestFile,
+}
+
+// handleConfigConvert 把项目配置转换为其他格式，写入新文件后删除原来的配置
+func handleConfigConvert(to string, force bool) error {
+	target, ok := configConvertTargets[strings.ToLower(to)]
+	if !ok {
+		return usageError("%s", msg("config.convert_usage"))
+	}
+
+	source := findProjectConfig()
+	if source == "" {
+		return configError(msg("config.not_found"), projectConfigFile())
+	}
+	if source == target {
+		return emitResult(&configConvertResult{From: source, To: target}, func() {
+			fmt.Println(msg("config.convert_same", source))
+		})
+	}
+	if !force && fileExists(target) && (target != ManifestFile || manifestHasSection(target)) {
+		return usageError(msg("config.convert_exists"), target)
+	}
+
+	doc, err := loadConfigDocument(source)
+	if err != nil {
+		return configError("%w", err)
+	}
+	if format, _ := configFormat(target); format == FormatYAML {
+		// JSON 中的引号和内联格式在 YAML 中改为普通的块格式
+		clearNodeStyle(doc)
+	}
+	if err := saveConfigDocument(target, doc); err != nil {
+		return configError("%w", err)
+	}
+
+	if err := removeProjectConfig(source); err != nil {
+		return configError(msg("config.convert_remove_failed"), target, source, err)
+	}
+	return emitResult(&configConvertResult{From: source, To: target}, func() {
+		fmt.Println(msg("config.converted", source, target))
+	})
+}
+
+// configConvertResult ghc config convert 的结果
+type configConvertResult struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// configMigrateResult ghc config migrate 的结果
+type configMigrateResult struct {
+	SchemaVersion int                 `json:"schema_version" yaml:"schema_version"`
+	Files         []configMigrateFile `json:"files" yaml:"files"` // 需要升级或已经升级的文件
+}
+
+// configMigrateFile 需要升级的配置文件
+type configMigrateFile struct {
+	Path       string   `json:"path" yaml:"path"`
+	From       int      `json:"from" yaml:"from"`
+	Backup     string   `json:"backup,omitempty" yaml:"backup,omitempty"` // 升级前的备份，--check 时为空
+	Migrations []string `json:"migrations" yaml:"migrations"`
+}
+
+// removeProjectConfig 删除原来的项目配置，package.json 只删除其中的 ghc 字段
+func removeProjectConfig(path string) error {
+	if path != ManifestFile {
+		if dryRunSkip(msg("dryrun.remove", path)) {
+			return nil
+		}
+		return os.Remove(path)
+	}
+
+	manifest, err := loadManifestDocument(path)
+	if err != nil {
+		return err
+	}
+	if _, i := mappingValue(manifest, manifestSection); i >= 0 {
+		manifest.Content = append(manifest.Content[:i], manifest.Content[i+2:]...)
+	}
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, manifest, ""); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	return writeFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// handleConfigMigrate 升级项目配置、本地配置和用户配置
+// --check 不修改文件，有需要升级的配置时以退出码 1 结束，用于 CI 检查
+func handleConfigMigrate(check bool) error {
+	paths := []string{findProjectConfig(), LocalConfigFile, globalConfigPath()}
+	result := &configMigrateResult{SchemaVersion: CurrentSchemaVersion, Files: []configMigrateFile{}}
+	for _, path := range paths {
+		if path == "" || !fileExists(path) {
+			continue
+		}
+		doc, err := loadConfigDocument(path)
+		if err != nil {
+			return configError("%w", err)
+		}
+
+		if !check {
+			file, err := migrateConfigFile(path, doc)
+			if err != nil {
+				return configError("%w", err)
+			}
+			if file != nil {
+				result.Files = append(result.Files, *file)
+			}
+			continue
+		}
+
+		// --check 在副本上执行迁移得到需要升级的内容，不修改文件
+		from, applied, err := migrateConfigDocument(copyConfigNode(doc))
+		if err != nil {
+			return configError("%s: %v", path, err)
+		}
+		if len(applied) > 0 {
+			result.Files = append(result.Files, *newConfigMigrateFile(path, from, applied))
+		}
+	}
+
+	err := emitResult(result, func() {
+		if len(result.Files) == 0 {
+			fmt.Println(msg("migrate.up_to_date", CurrentSchemaVersion))
+			return
+		}
+		for i := range result.Files {
+			file := &result.Files[i]
+			if check {
+				fmt.Println(msg("migrate.needed", file.Path, file.From, CurrentSchemaVersion))
+				printMigrations(file)
+			} else {
+				printConfigMigration(file)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if check && len(result.Files) > 0 {
+		return configError("%s", msg("migrate.run_hint"))
+	}
+	return nil
+}
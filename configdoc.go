@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configPathSegment 点分路径中的一段，Index >= 0 时表示数组下标
+type configPathSegment struct {
+	Key   string
+	Index int
+}
+
+// configPathPart 匹配 key、key[0] 或 0 形式的路径片段
+var configPathPart = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+
+// configPathIndex 匹配路径片段中的数组下标
+var configPathIndex = regexp.MustCompile(`\d+`)
+
+// parseConfigPath 解析 pre_build.timeout、remotes[0].url 或 remotes.0.url 形式的路径
+// 值为标量的 map（例如 forge_hosts）会把剩余部分整体作为键，以支持带点的主机名；
+// 值为对象的 map（例如 profiles.nightly.branch）只取一段作为键
+func parseConfigPath(path string) ([]configPathSegment, *schemaNode, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil, errors.New(msg("configpath.empty"))
+	}
+
+	var segments []configPathSegment
+	schema := configSchema
+	parts := strings.Split(path, ".")
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+
+		if schema.Type == "map" {
+			// 值为对象的 map（例如 profiles、packages）只取一段作为键，继续按值的模式解析后面的部分
+			if schema.Values.Type == "object" || schema.Values.Type == "array" {
+				if part == "" {
+					return nil, nil, fmt.Errorf(msg("configpath.invalid"), path)
+				}
+				segments = append(segments, configPathSegment{Key: part, Index: -1})
+				schema = schema.Values
+				continue
+			}
+			key := strings.Join(parts[i:], ".")
+			segments = append(segments, configPathSegment{Key: key, Index: -1})
+			return segments, schema.Values, nil
+		}
+
+		m := configPathPart.FindStringSubmatch(part)
+		if m == nil {
+			return nil, nil, fmt.Errorf(msg("configpath.invalid"), path)
+		}
+
+		if m[1] != "" {
+			if n, err := strconv.Atoi(m[1]); err == nil && schema.Type == "array" {
+				segments = append(segments, configPathSegment{Index: n})
+				schema = schema.Items
+			} else {
+				if schema.Type != "object" {
+					return nil, nil, fmt.Errorf(msg("configpath.not_object"), strings.Join(parts[:i], "."), m[1])
+				}
+				field := schema.field(m[1])
+				if field == nil {
+					prefix := strings.Join(parts[:i], ".")
+					if suggestion := suggestField(schema, m[1]); suggestion != "" {
+						return nil, nil, fmt.Errorf(msg("configpath.unknown_suggest"), joinSchemaPath(prefix, m[1]), joinSchemaPath(prefix, suggestion))
+					}
+					return nil, nil, fmt.Errorf(msg("configpath.unknown"), joinSchemaPath(prefix, m[1]))
+				}
+				segments = append(segments, configPathSegment{Key: m[1], Index: -1})
+				schema = field
+			}
+		}
+
+		for _, index := range configPathIndex.FindAllString(m[2], -1) {
+			if schema.Type != "array" {
+				return nil, nil, fmt.Errorf(msg("configpath.not_array"), path)
+			}
+			n, _ := strconv.Atoi(index)
+			segments = append(segments, configPathSegment{Index: n})
+			schema = schema.Items
+		}
+	}
+	return segments, schema, nil
+}
+
+// loadConfigDocument 读取配置文件的 YAML 节点树，保留注释和键的顺序
+// TOML 和 JSON 格式的配置同样转换为节点树
+func loadConfigDocument(path string) (*yaml.Node, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(msg("config.read_failed"), err)
+	}
+
+	doc, err := decodeConfigData(path, data)
+	if err != nil {
+		return nil, fmt.Errorf(msg("config.parse_failed"), err)
+	}
+	if doc.Kind == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	return doc, nil
+}
+
+// encodeConfigDocument 把节点树序列化为缩进两个空格的 YAML
+func encodeConfigDocument(doc *yaml.Node) ([]byte, error) {
+	return encodeConfigDocumentIndent(doc, 2)
+}
+
+// encodeConfigDocumentIndent 按指定的缩进宽度把节点树序列化为 YAML
+func encodeConfigDocumentIndent(doc *yaml.Node, indent int) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// saveConfigDocument 校验节点树后写回配置文件，校验失败时不修改文件
+func saveConfigDocument(path string, doc *yaml.Node) error {
+	if errs := validateConfigDocument(path, doc); len(errs) > 0 {
+		return fmt.Errorf(msg("config.edit_invalid"), errs)
+	}
+
+	data, err := encodeConfigData(path, doc)
+	if err != nil {
+		return fmt.Errorf(msg("config.encode_failed"), err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf(msg("config.write_failed"), err)
+	}
+	return nil
+}
+
+// mappingValue 在映射节点中查找键对应的值节点，返回值节点和键所在的下标
+func mappingValue(mapping *yaml.Node, key string) (*yaml.Node, int) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1], i
+		}
+	}
+	return nil, -1
+}
+
+// lookupConfigNode 按路径查找节点，不存在时返回 nil
+func lookupConfigNode(doc *yaml.Node, segments []configPathSegment) *yaml.Node {
+	node := doc.Content[0]
+	for _, segment := range segments {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		if segment.Index >= 0 {
+			if node.Kind != yaml.SequenceNode || segment.Index >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[segment.Index]
+			continue
+		}
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		value, _ := mappingValue(node, segment.Key)
+		if value == nil {
+			return nil
+		}
+		node = value
+	}
+	return node
+}
+
+// setConfigNode 按路径设置节点的值，缺少的中间对象会被创建
+// 已存在的标量只修改值，保留其行尾注释
+func setConfigNode(doc *yaml.Node, segments []configPathSegment, value *yaml.Node) error {
+	parent := doc.Content[0]
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		if segment.Index >= 0 {
+			if parent.Kind != yaml.SequenceNode {
+				return fmt.Errorf(msg("configpath.index_not_array"), segment.Index)
+			}
+			switch {
+			case segment.Index < len(parent.Content):
+			case segment.Index == len(parent.Content):
+				// 下标等于数组长度时追加新元素
+				parent.Content = append(parent.Content, newContainerNode(segments, i))
+			default:
+				return fmt.Errorf(msg("configpath.index_out_of_range"), segment.Index, len(parent.Content))
+			}
+			if last {
+				replaceNodeValue(parent.Content[segment.Index], value)
+				return nil
+			}
+			parent = parent.Content[segment.Index]
+			continue
+		}
+
+		if parent.Kind == yaml.ScalarNode && parent.Tag == "!!null" {
+			*parent = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: parent.LineComment}
+		}
+		if parent.Kind != yaml.MappingNode {
+			return fmt.Errorf(msg("configpath.parent_not_object"), segment.Key)
+		}
+		existing, _ := mappingValue(parent, segment.Key)
+		if existing == nil {
+			existing = newContainerNode(segments, i)
+			parent.Content = append(parent.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment.Key}, existing)
+		}
+		if last {
+			replaceNodeValue(existing, value)
+			return nil
+		}
+		parent = existing
+	}
+	return nil
+}
+
+// newContainerNode 为路径中第 i 段创建占位节点，下一段为下标时创建数组，否则创建对象
+func newContainerNode(segments []configPathSegment, i int) *yaml.Node {
+	if i+1 < len(segments) && segments[i+1].Index >= 0 {
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	if i+1 < len(segments) {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+}
+
+// replaceNodeValue 用新值替换节点内容，保留原节点的注释
+func replaceNodeValue(node, value *yaml.Node) {
+	head, line, foot := node.HeadComment, node.LineComment, node.FootComment
+	style := node.Style
+	*node = *value
+	node.HeadComment, node.LineComment, node.FootComment = head, line, foot
+	// 保留原有的引号风格，除非新值必须使用其他风格
+	if value.Kind == yaml.ScalarNode && value.Style == 0 && (style == yaml.DoubleQuotedStyle || style == yaml.SingleQuotedStyle) && value.Tag == "!!str" {
+		node.Style = style
+	}
+}
+
+// unsetConfigNode 按路径删除节点，返回是否删除了内容
+func unsetConfigNode(doc *yaml.Node, segments []configPathSegment) bool {
+	parent := lookupConfigNode(doc, segments[:len(segments)-1])
+	if parent == nil {
+		return false
+	}
+
+	segment := segments[len(segments)-1]
+	if segment.Index >= 0 {
+		if parent.Kind != yaml.SequenceNode || segment.Index >= len(parent.Content) {
+			return false
+		}
+		parent.Content = append(parent.Content[:segment.Index], parent.Content[segment.Index+1:]...)
+		return true
+	}
+
+	if parent.Kind != yaml.MappingNode {
+		return false
+	}
+	_, i := mappingValue(parent, segment.Key)
+	if i < 0 {
+		return false
+	}
+	parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+	// 删除后为空的上级对象一并删除，避免留下 forge_hosts: {}
+	if len(parent.Content) == 0 && len(segments) > 1 {
+		unsetConfigNode(doc, segments[:len(segments)-1])
+	}
+	return true
+}
+
+// coerceConfigValue 按模式把命令行中的字符串转换为 YAML 节点
+func coerceConfigValue(schema *schemaNode, raw string) (*yaml.Node, error) {
+	switch schema.Type {
+	case "string":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}, nil
+	case "integer":
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf(msg("configvalue.integer"), raw)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(n)}, nil
+	case "boolean":
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf(msg("configvalue.boolean"), raw)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(b)}, nil
+	}
+
+	// 数组和对象以 YAML（或 JSON）形式给出，例如 '["go vet ./..."]'
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil || len(doc.Content) == 0 {
+		if schema.Type == "array" {
+			return nil, fmt.Errorf(msg("configvalue.yaml_array"), raw)
+		}
+		return nil, fmt.Errorf(msg("configvalue.yaml_object"), raw)
+	}
+	// JSON 形式的值带有流式格式和引号，清除后按块格式写入，与文件中其余部分保持一致
+	value := doc.Content[0]
+	clearNodeStyle(value)
+	return value, nil
+}
+
+// flattenConfigNode 把节点树展开为点分路径和值，按文件中的顺序排列
+func flattenConfigNode(node *yaml.Node, path string, visit func(path string, value *yaml.Node)) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 && path != "" {
+			visit(path, node)
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			flattenConfigNode(node.Content[i+1], joinSchemaPath(path, node.Content[i].Value), visit)
+		}
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			visit(path, node)
+		}
+		for i, item := range node.Content {
+			flattenConfigNode(item, fmt.Sprintf("%s[%d]", path, i), visit)
+		}
+	default:
+		visit(path, node)
+	}
+}
+
+// formatConfigValue 把节点格式化为单行文本
+func formatConfigValue(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			return ""
+		}
+		return node.Value
+	}
+	copied := *node
+	copied.Style = yaml.FlowStyle
+	data, err := yaml.Marshal(&copied)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// mergeConfigNode 把 desired 中的值以最小改动合并到 existing 中
+// 语义相同的节点保持原样，已有节点只修改值，从而保留注释、键的顺序和引号风格
+func mergeConfigNode(existing, desired *yaml.Node) {
+	if sameNodeValue(existing, desired) {
+		return
+	}
+	if existing.Kind != desired.Kind {
+		replaceNodeValue(existing, desired)
+		return
+	}
+
+	switch existing.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(desired.Content); i += 2 {
+			key, value := desired.Content[i], desired.Content[i+1]
+			if current, _ := mappingValue(existing, key.Value); current != nil {
+				mergeConfigNode(current, value)
+			} else if !isZeroNode(value) {
+				// 文件中没有的键只在取值非空时追加，避免写入大量默认值
+				existing.Content = append(existing.Content, key, value)
+			}
+		}
+		// desired 中省略（omitempty）的键，文件中保留为空值时不动，否则删除
+		for i := 0; i+1 < len(existing.Content); {
+			if value, _ := mappingValue(desired, existing.Content[i].Value); value == nil && !isZeroNode(existing.Content[i+1]) {
+				existing.Content = append(existing.Content[:i], existing.Content[i+2:]...)
+				continue
+			}
+			i += 2
+		}
+	case yaml.SequenceNode:
+		for i, item := range desired.Content {
+			if i < len(existing.Content) {
+				mergeConfigNode(existing.Content[i], item)
+			} else {
+				existing.Content = append(existing.Content, item)
+			}
+		}
+		if len(existing.Content) > len(desired.Content) {
+			existing.Content = existing.Content[:len(desired.Content)]
+		}
+	default:
+		replaceNodeValue(existing, desired)
+	}
+}
+
+// sameNodeValue 判断两个节点解码后的值是否相同
+func sameNodeValue(a, b *yaml.Node) bool {
+	var va, vb interface{}
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// isZeroNode 判断节点是否为空值：null、空字符串、false、0，或只包含空值的对象和数组
+func isZeroNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var v interface{}
+		if node.Decode(&v) != nil {
+			return false
+		}
+		return v == nil || reflect.ValueOf(v).IsZero()
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if !isZeroNode(node.Content[i]) {
+				return false
+			}
+		}
+		return true
+	case yaml.SequenceNode:
+		return len(node.Content) == 0
+	}
+	return false
+}
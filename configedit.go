@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scalarEdit 需要在原文件中替换的一个标量
+type scalarEdit struct {
+	original *yaml.Node // 原文件中的节点，提供行列号
+	value    *yaml.Node // 修改后的节点
+	flow     bool       // 位于 [a, b] 或 {k: v} 形式的内联集合中
+}
+
+// spliceConfigScalars 修改后的节点树与原文件相比只有标量值变化时，把新值直接替换到原文件的字节中，
+// 其余行的缩进、空白和注释保持不变；增删了键或元素、使用了块标量等情况返回 false
+func spliceConfigScalars(original []byte, doc *yaml.Node) ([]byte, bool) {
+	var parsed yaml.Node
+	if err := yaml.Unmarshal(original, &parsed); err != nil {
+		return nil, false
+	}
+	var edits []scalarEdit
+	if !collectScalarEdits(&parsed, doc, false, &edits) {
+		return nil, false
+	}
+
+	// 从后向前替换，前面的偏移量不受影响
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, 0, len(edits))
+	lines := lineOffsets(original)
+	for _, edit := range edits {
+		start, end, ok := scalarRange(original, lines, edit.original)
+		if !ok {
+			return nil, false
+		}
+		text, ok := renderScalar(edit.value, edit.flow)
+		if !ok {
+			return nil, false
+		}
+		spans = append(spans, span{start, end, text})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+
+	data := append([]byte(nil), original...)
+	for i, s := range spans {
+		if i > 0 && s.end > spans[i-1].start {
+			return nil, false
+		}
+		data = append(data[:s.start], append([]byte(s.text), data[s.end:]...)...)
+	}
+	return data, true
+}
+
+// collectScalarEdits 同时遍历原节点树和修改后的节点树，记录值发生变化的标量；结构不同时返回 false
+func collectScalarEdits(original, changed *yaml.Node, flow bool, edits *[]scalarEdit) bool {
+	if original.Kind != changed.Kind {
+		return false
+	}
+	switch original.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode, yaml.MappingNode:
+		if len(original.Content) != len(changed.Content) {
+			return false
+		}
+		flow = flow || original.Style&yaml.FlowStyle != 0
+		for i := range original.Content {
+			// 映射的键必须保持不变
+			if original.Kind == yaml.MappingNode && i%2 == 0 {
+				if original.Content[i].Value != changed.Content[i].Value {
+					return false
+				}
+				continue
+			}
+			if !collectScalarEdits(original.Content[i], changed.Content[i], flow, edits) {
+				return false
+			}
+		}
+		return true
+	case yaml.AliasNode:
+		return original.Value == changed.Value
+	case yaml.ScalarNode:
+		if original.Value == changed.Value && original.Tag == changed.Tag && original.Style == changed.Style {
+			return true
+		}
+		if original.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || original.Anchor != "" {
+			return false
+		}
+		*edits = append(*edits, scalarEdit{original: original, value: changed, flow: flow})
+		return true
+	}
+	return false
+}
+
+// lineOffsets 返回每一行起始位置的字节偏移
+func lineOffsets(data []byte) []int {
+	offsets := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// scalarRange 返回标量在原文件中的字节范围，包括引号；无法确定时返回 false
+func scalarRange(data []byte, lines []int, node *yaml.Node) (int, int, bool) {
+	if node.Line < 1 || node.Line > len(lines) || node.Column < 1 {
+		return 0, 0, false
+	}
+	// 列号按字符计算，需要转换为字节偏移
+	start := lines[node.Line-1]
+	for i := 1; i < node.Column; i++ {
+		if start >= len(data) || data[start] == '\n' {
+			return 0, 0, false
+		}
+		_, size := utf8.DecodeRune(data[start:])
+		start += size
+	}
+
+	switch node.Style &^ yaml.FlowStyle {
+	case yaml.DoubleQuotedStyle:
+		if start >= len(data) || data[start] != '"' {
+			return 0, 0, false
+		}
+		for i := start + 1; i < len(data); i++ {
+			switch data[i] {
+			case '\\':
+				i++
+			case '"':
+				return start, i + 1, true
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		if start >= len(data) || data[start] != '\'' {
+			return 0, 0, false
+		}
+		for i := start + 1; i < len(data); i++ {
+			if data[i] == '\'' {
+				if i+1 < len(data) && data[i+1] == '\'' {
+					i++
+					continue
+				}
+				return start, i + 1, true
+			}
+		}
+	case 0:
+		// 普通标量只处理与值完全一致的单行文本，例如 version: 1.0.0
+		if node.Value != "" && bytes.HasPrefix(data[start:], []byte(node.Value)) && !strings.Contains(node.Value, "\n") {
+			return start, start + len(node.Value), true
+		}
+	}
+	return 0, 0, false
+}
+
+// renderScalar 把标量序列化为单行文本，位于内联集合中时为含有分隔符的值加上引号
+func renderScalar(node *yaml.Node, flow bool) (string, bool) {
+	scalar := yaml.Node{Kind: yaml.ScalarNode, Tag: node.Tag, Value: node.Value, Style: node.Style &^ yaml.FlowStyle}
+	if flow && scalar.Style == 0 && strings.ContainsAny(scalar.Value, ",[]{}") {
+		scalar.Style = yaml.DoubleQuotedStyle
+	}
+	data, err := yaml.Marshal(&scalar)
+	if err != nil {
+		return "", false
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	if strings.Contains(text, "\n") {
+		return "", false
+	}
+	return text, true
+}
+
+// commentSpacing 匹配行尾注释前的空白
+var commentSpacing = regexp.MustCompile(`[ \t]+#`)
+
+// restoreOriginalLines 重新序列化会把行尾注释前的空白压缩为一个空格，
+// 对于只有这一处不同的行，改回原文件中的写法
+func restoreOriginalLines(original, encoded []byte) []byte {
+	originals := make(map[string]string)
+	for _, line := range strings.Split(string(original), "\n") {
+		originals[commentSpacing.ReplaceAllString(line, " #")] = line
+	}
+	lines := strings.Split(string(encoded), "\n")
+	for i, line := range lines {
+		if restored, ok := originals[line]; ok {
+			lines[i] = restored
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// yamlIndent 推断 YAML 文件的缩进宽度，取第一个缩进行的空格数，找不到时为 2
+func yamlIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || len(trimmed) == len(line) {
+			continue
+		}
+		if n := len(line) - len(trimmed); n >= 2 && n <= 8 {
+			return n
+		}
+		break
+	}
+	return 2
+}
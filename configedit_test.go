@@ -0,0 +1,317 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fourSpaceConfig 使用四个空格缩进、行尾注释前有多个空格的配置
+const fourSpaceConfig = `# 项目配置
+schema_version: 2
+repo: "https://github.com/owner/repo.git"   # 仓库地址
+branch: main
+auto_push: true
+build_command: go build ./...
+version: 1.0.0      # 当前版本
+tag_prefix: v
+pre_build:
+    enabled: true       # 启用预编译
+    timeout: 300
+    steps:
+        - id: vet
+          run: go vet ./...
+`
+
+// setupConfigDir 在临时目录中写入项目配置并切换到该目录，隔离用户配置和环境变量
+func setupConfigDir(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.WriteFile(ConfigFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// changedLines 返回两个文本中内容不同的行
+func changedLines(before, after string) []string {
+	a, b := strings.Split(before, "\n"), strings.Split(after, "\n")
+	var changed []string
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			changed = append(changed, "+"+b[i])
+		case i >= len(b):
+			changed = append(changed, "-"+a[i])
+		case a[i] != b[i]:
+			changed = append(changed, a[i]+" => "+b[i])
+		}
+	}
+	return changed
+}
+
+func TestSaveConfigChangesOnlyVersionLine(t *testing.T) {
+	setupConfigDir(t, fourSpaceConfig)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	config.Version = "1.0.1"
+	if err := SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	data, _ := os.ReadFile(ConfigFile)
+	want := strings.Replace(fourSpaceConfig, "version: 1.0.0      # 当前版本", "version: 1.0.1      # 当前版本", 1)
+	if string(data) != want {
+		t.Errorf("SaveConfig changed more than the version line: %q", changedLines(fourSpaceConfig, string(data)))
+	}
+}
+
+func TestSaveConfigKeepsQuotesAndIndent(t *testing.T) {
+	setupConfigDir(t, fourSpaceConfig)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	config.Repo = "https://gitlab.com/owner/repo.git"
+	config.PreBuild.Timeout = 600
+	if err := SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	data, _ := os.ReadFile(ConfigFile)
+	changed := changedLines(fourSpaceConfig, string(data))
+	want := []string{
+		`repo: "https://github.com/owner/repo.git"   # 仓库地址 => repo: "https://gitlab.com/owner/repo.git"   # 仓库地址`,
+		`    timeout: 300 =>     timeout: 600`,
+	}
+	if strings.Join(changed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changed lines = %q, want %q", changed, want)
+	}
+}
+
+func TestSaveConfigStructuralChangeKeepsIndent(t *testing.T) {
+	setupConfigDir(t, fourSpaceConfig)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	config.Release.Enabled = true
+	if err := SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	// 新增的键需要重新序列化，此时沿用文件原有的缩进和注释前的空白，只追加 release 的两行
+	data, _ := os.ReadFile(ConfigFile)
+	changed := changedLines(fourSpaceConfig, string(data))
+	want := []string{" => release:", "+    enabled: true", "+"}
+	if strings.Join(changed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changed lines = %q, want %q\n%s", changed, want, data)
+	}
+}
+
+func TestSpliceConfigScalars(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		path   string
+		tag    string
+		value  string
+		output string
+	}{
+		{"plain", "a:\n    b: 1   # note\n", "a.b", "!!int", "2", "a:\n    b: 2   # note\n"},
+		{"double quoted", "a: \"x\\\"y\"  # 注释\nb: 1\n", "a", "!!str", "z", "a: \"z\"  # 注释\nb: 1\n"},
+		{"single quoted", "a: 'it''s'\nb: 1\n", "a", "!!str", "ok", "a: 'ok'\nb: 1\n"},
+		{"flow sequence", "a: [x, y]   # list\n", "a[1]", "!!str", "p, q", "a: [x, \"p, q\"]   # list\n"},
+		{"needs quotes", "a: x\n", "a", "!!str", "true", "a: \"true\"\n"},
+		{"after multibyte", "说明: {a: 1, b: 2}\n", "说明.b", "!!int", "3", "说明: {a: 1, b: 3}\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(tc.input), &doc); err != nil {
+				t.Fatal(err)
+			}
+			var segments []configPathSegment
+			for _, part := range strings.Split(strings.ReplaceAll(tc.path, "[", ".["), ".") {
+				if strings.HasPrefix(part, "[") {
+					segments = append(segments, configPathSegment{Index: int(part[1] - '0')})
+				} else {
+					segments = append(segments, configPathSegment{Key: part, Index: -1})
+				}
+			}
+			if err := setConfigNode(&doc, segments, &yaml.Node{Kind: yaml.ScalarNode, Tag: tc.tag, Value: tc.value}); err != nil {
+				t.Fatal(err)
+			}
+
+			data, ok := spliceConfigScalars([]byte(tc.input), &doc)
+			if !ok {
+				t.Fatal("spliceConfigScalars fell back to re-encoding")
+			}
+			if string(data) != tc.output {
+				t.Errorf("got %q, want %q", data, tc.output)
+			}
+		})
+	}
+}
+
+func TestSpliceConfigScalarsRejectsStructuralChanges(t *testing.T) {
+	input := "a: 1\n"
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(input), &doc); err != nil {
+		t.Fatal(err)
+	}
+	setConfigNode(&doc, []configPathSegment{{Key: "b", Index: -1}}, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "2"})
+	if _, ok := spliceConfigScalars([]byte(input), &doc); ok {
+		t.Error("added key was spliced instead of re-encoded")
+	}
+}
+
+// profileConfig 定义了 nightly 方案的配置
+const profileConfig = `schema_version: 2
+repo: "https://github.com/owner/repo.git"
+branch: main
+version: 1.0.0
+tag_prefix: v
+profiles:
+  nightly:
+    branch: develop # 每日构建分支
+    tag_prefix: nightly-
+`
+
+func TestParseConfigPathMapOfObjects(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		typ  string
+	}{
+		{"profiles.nightly.branch", "profiles.nightly.branch", "string"},
+		{"profiles.nightly.remotes[0].url", "profiles.nightly.remotes[0].url", "string"},
+		{"packages.api.version", "packages.api.version", "string"},
+		{"forge_hosts.git.example.com", "forge_hosts.git.example.com", "string"},
+	}
+	for _, tc := range tests {
+		segments, schema, err := parseConfigPath(tc.path)
+		if err != nil {
+			t.Errorf("parseConfigPath(%q): %v", tc.path, err)
+			continue
+		}
+		if got := formatConfigPath(segments); got != tc.want || schema.Type != tc.typ {
+			t.Errorf("parseConfigPath(%q) = %q (%s), want %q (%s)", tc.path, got, schema.Type, tc.want, tc.typ)
+		}
+	}
+	if _, _, err := parseConfigPath("profiles.nightly.brnch"); err == nil {
+		t.Error("unknown profile field was accepted")
+	}
+}
+
+func TestConfigProfileFieldGetSetUnset(t *testing.T) {
+	setupConfigDir(t, profileConfig)
+
+	if err := handleConfigGet("", "profiles.nightly.branch"); err != nil {
+		t.Fatalf("config get: %v", err)
+	}
+	if err := handleConfigSet("", "profiles.nightly.branch", "release"); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	data, _ := os.ReadFile(ConfigFile)
+	want := []string{"    branch: develop # 每日构建分支 =>     branch: release # 每日构建分支"}
+	if changed := changedLines(profileConfig, string(data)); strings.Join(changed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("config set changed lines = %q, want %q", changed, want)
+	}
+
+	if err := handleConfigUnset("", "profiles.nightly.tag_prefix"); err != nil {
+		t.Fatalf("config unset: %v", err)
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := config.Profiles["nightly"]
+	if profile.Branch != "release" || profile.TagPrefix != "" {
+		t.Errorf("profile after set/unset = branch %q, tag_prefix %q; want release and empty", profile.Branch, profile.TagPrefix)
+	}
+	if err := handleConfigGet("", "profiles.nightly.tag_prefix"); err == nil {
+		t.Error("config get of an unset profile field succeeded")
+	}
+}
+
+// packageConfig 定义了 api 包的 monorepo 配置
+const packageConfig = `schema_version: 2
+repo: "https://github.com/owner/repo.git"
+branch: main
+version: 1.0.0
+packages:
+  api:
+    path: services/api
+    version: 1.1.0 # api 的版本
+`
+
+func TestConfigPackageFieldSet(t *testing.T) {
+	setupConfigDir(t, packageConfig)
+
+	if err := handleConfigSet("", "packages.api.version", "1.2.0"); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	data, _ := os.ReadFile(ConfigFile)
+	want := []string{"    version: 1.1.0 # api 的版本 =>     version: 1.2.0 # api 的版本"}
+	if changed := changedLines(packageConfig, string(data)); strings.Join(changed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("config set changed lines = %q, want %q", changed, want)
+	}
+
+	// 新增的字段写入包的对象中，而不是名为 api.build_command 的键
+	if err := handleConfigSet("", "packages.api.build_command", "go build ./..."); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg := config.Packages["api"]; pkg.Version != "1.2.0" || pkg.BuildCommand != "go build ./..." || len(config.Packages) != 1 {
+		t.Errorf("packages after set = %+v", config.Packages)
+	}
+
+	if err := handleConfigUnset("", "packages.api.build_command"); err != nil {
+		t.Fatalf("config unset: %v", err)
+	}
+	if err := handleConfigGet("", "packages.api.build_command"); err == nil {
+		t.Error("config get of an unset package field succeeded")
+	}
+}
+
+func TestConfigSetJSONValueWritesBlockStyle(t *testing.T) {
+	setupConfigDir(t, packageConfig)
+
+	if err := handleConfigSet("", "remotes", `[{"name": "mirror", "url": "https://gitlab.com/owner/repo.git", "role": "mirror"}]`); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	data, _ := os.ReadFile(ConfigFile)
+	want := packageConfig + `remotes:
+  - name: mirror
+    url: https://gitlab.com/owner/repo.git
+    role: mirror
+`
+	if string(data) != want {
+		t.Errorf("config after set =\n%s\nwant\n%s", data, want)
+	}
+
+	// 去掉引号后会被解析成其他类型的字符串仍然保留引号
+	if err := handleConfigSet("", "pre_build.steps", `[{"id": "ok", "run": "true"}]`); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if steps := config.PreBuild.Steps; len(steps) != 1 || steps[0].Run != "true" {
+		t.Errorf("pre_build.steps after set = %+v", steps)
+	}
+}
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的配置文件格式
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+	FormatJSON = "json"
+)
+
+// ManifestFile 可以在 ghc 字段中存放配置的项目清单
+const ManifestFile = "package.json"
+
+// manifestSection 项目清单中存放 ghc 配置的字段
+const manifestSection = "ghc"
+
+// projectConfigFiles 按优先级查找的项目配置文件
+var projectConfigFiles = []string{ConfigFile, "ghc.config.yml", "ghc.config.toml", "ghc.config.json", ManifestFile}
+
+// findProjectConfig 返回存在的项目配置文件，package.json 只在包含 ghc 字段时使用
+// 指定了 --config 时只使用该文件
+func findProjectConfig() string {
+	if configPath != "" {
+		if !fileExists(configPath) {
+			return ""
+		}
+		return configPath
+	}
+	for _, path := range projectConfigFiles {
+		if !fileExists(path) {
+			continue
+		}
+		if path == ManifestFile && !manifestHasSection(path) {
+			continue
+		}
+		return path
+	}
+	return ""
+}
+
+// projectConfigFile 返回项目配置文件，不存在时返回 --config 指定的文件或默认的 ghc.config.yaml
+func projectConfigFile() string {
+	if configPath != "" {
+		return configPath
+	}
+	if path := findProjectConfig(); path != "" {
+		return path
+	}
+	return ConfigFile
+}
+
+// manifestHasSection 判断项目清单中是否有 ghc 字段
+func manifestHasSection(path string) bool {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false
+	}
+	_, ok := manifest[manifestSection]
+	return ok
+}
+
+// configFormat 根据文件名判断配置格式
+func configFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf(msg("config.unsupported_format"), path)
+}
+
+// decodeConfigData 把任意格式的配置解析为 YAML 节点树，之后统一按模式校验
+// YAML 和 JSON 保留行列号；package.json 只取 ghc 字段
+func decodeConfigData(path string, data []byte) (*yaml.Node, error) {
+	format, err := configFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatTOML:
+		var values map[string]interface{}
+		if _, err := toml.Decode(string(data), &values); err != nil {
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, ConfigErrors{{File: path, Line: parseErr.Position.Line, Column: parseErr.Position.Col, Message: parseErr.Message}}
+			}
+			return nil, ConfigErrors{{File: path, Message: err.Error()}}
+		}
+		root := valueToConfigNode(values, configSchema)
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, nil
+	}
+
+	// JSON 是 YAML 的子集，使用同一个解析器以保留位置信息
+	doc, err := parseConfigDocument(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Base(path) == ManifestFile {
+		if doc.Kind == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			return nil, ConfigErrors{{File: path, Message: msg("config.manifest_not_object")}}
+		}
+		section, _ := mappingValue(doc.Content[0], manifestSection)
+		if section == nil {
+			return nil, ConfigErrors{{File: path, Message: msg("config.manifest_missing_section")}}
+		}
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{section}}, nil
+	}
+	return doc, nil
+}
+
+// encodeConfigData 把节点树序列化为文件对应的格式
+// package.json 只替换其中的 ghc 字段，其余内容和字段顺序保持不变
+func encodeConfigData(path string, doc *yaml.Node) ([]byte, error) {
+	format, err := configFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatTOML:
+		return encodeTOML(doc.Content[0])
+	case FormatJSON:
+		root := doc.Content[0]
+		if filepath.Base(path) == ManifestFile {
+			manifest, err := loadManifestDocument(path)
+			if err != nil {
+				return nil, err
+			}
+			if section, _ := mappingValue(manifest, manifestSection); section != nil {
+				*section = *root
+			} else {
+				manifest.Content = append(manifest.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: manifestSection}, root)
+			}
+			root = manifest
+		}
+		var buf bytes.Buffer
+		if err := writeJSONNode(&buf, root, ""); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+
+	// YAML 文件已存在时只替换修改过的标量，其余内容保持原样；结构有变化时按原文件的缩进重新序列化
+	if original, err := ioutil.ReadFile(path); err == nil {
+		if data, ok := spliceConfigScalars(original, doc); ok {
+			return data, nil
+		}
+		data, err := encodeConfigDocumentIndent(doc, yamlIndent(original))
+		if err != nil {
+			return nil, err
+		}
+		return restoreOriginalLines(original, data), nil
+	}
+	return encodeConfigDocument(doc)
+}
+
+// loadManifestDocument 读取项目清单的根对象，文件不存在时返回空对象
+func loadManifestDocument(path string) (*yaml.Node, error) {
+	if !fileExists(path) {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(msg("config.manifest_read_failed"), path, err)
+	}
+	doc, err := parseConfigDocument(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf(msg("config.manifest_file_not_object"), path)
+	}
+	return doc.Content[0], nil
+}
+
+// valueToConfigNode 把解码后的值转换为 YAML 节点，对象的键按模式中的顺序排列
+func valueToConfigNode(value interface{}, schema *schemaNode) *yaml.Node {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		var keys []string
+		if schema != nil && schema.Type == "object" {
+			for _, field := range schema.Fields {
+				if _, ok := v[field.Name]; ok {
+					keys = append(keys, field.Name)
+				}
+			}
+		}
+		var rest []string
+		for key := range v {
+			if !containsString(keys, key) {
+				rest = append(rest, key)
+			}
+		}
+		sort.Strings(rest)
+		for _, key := range append(keys, rest...) {
+			var child *schemaNode
+			if schema != nil {
+				switch schema.Type {
+				case "object":
+					child = schema.field(key)
+				case "map":
+					child = schema.Values
+				}
+			}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+				valueToConfigNode(v[key], child))
+		}
+		return node
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return valueToConfigNode(items, schema)
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		var items *schemaNode
+		if schema != nil {
+			items = schema.Items
+		}
+		for _, item := range v {
+			node.Content = append(node.Content, valueToConfigNode(item, items))
+		}
+		return node
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}
+	case int64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(v, 10)}
+	case float64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(v, 'g', -1, 64)}
+	case time.Time:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v.Format(time.RFC3339)}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+}
+
+// clearNodeStyle 清除节点的引号和内联格式，序列化时由编码器决定格式
+func clearNodeStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearNodeStyle(child)
+	}
+}
+
+// writeJSONNode 按节点顺序输出缩进两个空格的 JSON
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node, indent string) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			buf.WriteString(indent + "  ")
+			writeJSONString(buf, node.Content[i].Value)
+			buf.WriteString(": ")
+			if err := writeJSONNode(buf, node.Content[i+1], indent+"  "); err != nil {
+				return err
+			}
+			if i+2 < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range node.Content {
+			buf.WriteString(indent + "  ")
+			if err := writeJSONNode(buf, item, indent+"  "); err != nil {
+				return err
+			}
+			if i+1 < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!null":
+			buf.WriteString("null")
+		case "!!bool", "!!int", "!!float":
+			var v interface{}
+			if err := node.Decode(&v); err != nil {
+				return err
+			}
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			buf.Write(data)
+		default:
+			writeJSONString(buf, node.Value)
+		}
+	default:
+		return fmt.Errorf(msg("config.json_unsupported_node"), node.Line)
+	}
+	return nil
+}
+
+// writeJSONString 输出 JSON 字符串，不转义 HTML 字符
+func writeJSONString(buf *bytes.Buffer, s string) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	buf.Write(bytes.TrimRight(out.Bytes(), "\n"))
+}
+
+// encodeTOML 按节点顺序输出 TOML：先输出标量和数组，再输出子表和表数组
+func encodeTOML(root *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeTOMLTable(&buf, root, nil); err != nil {
+		return nil, err
+	}
+	return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+}
+
+// writeTOMLTable 输出一个表，keys 为表的完整路径
+func writeTOMLTable(buf *bytes.Buffer, table *yaml.Node, keys []string) error {
+	var tables, tableArrays []int
+	for i := 0; i+1 < len(table.Content); i += 2 {
+		key, value := table.Content[i], table.Content[i+1]
+		switch {
+		case value.Kind == yaml.MappingNode:
+			tables = append(tables, i)
+			continue
+		case isTableArray(value):
+			tableArrays = append(tableArrays, i)
+			continue
+		case value.Kind == yaml.ScalarNode && value.Tag == "!!null":
+			// TOML 没有空值，省略该键
+			continue
+		}
+		if key.HeadComment != "" {
+			buf.WriteString(tomlComment(key.HeadComment))
+		}
+		buf.WriteString(tomlKey(key.Value) + " = ")
+		if err := writeTOMLValue(buf, value); err != nil {
+			return fmt.Errorf("%s: %v", strings.Join(append(keys, key.Value), "."), err)
+		}
+		if value.LineComment != "" {
+			buf.WriteString(" " + value.LineComment)
+		}
+		buf.WriteByte('\n')
+	}
+
+	for _, i := range tables {
+		key, value := table.Content[i], table.Content[i+1]
+		path := append(append([]string{}, keys...), tomlKey(key.Value))
+		buf.WriteByte('\n')
+		if key.HeadComment != "" {
+			buf.WriteString(tomlComment(key.HeadComment))
+		}
+		buf.WriteString("[" + strings.Join(path, ".") + "]\n")
+		if err := writeTOMLTable(buf, value, path); err != nil {
+			return err
+		}
+	}
+	for _, i := range tableArrays {
+		key, value := table.Content[i], table.Content[i+1]
+		path := append(append([]string{}, keys...), tomlKey(key.Value))
+		for _, item := range value.Content {
+			buf.WriteString("\n[[" + strings.Join(path, ".") + "]]\n")
+			if err := writeTOMLTable(buf, item, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isTableArray 判断数组是否只包含对象，这样的数组以 [[key]] 形式输出
+func isTableArray(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTOMLValue 输出标量或内联数组
+func writeTOMLValue(buf *bytes.Buffer, node *yaml.Node) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeTOMLValue(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(tomlKey(node.Content[i].Value) + " = ")
+			if err := writeTOMLValue(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!bool", "!!int", "!!float":
+			buf.WriteString(node.Value)
+		case "!!null":
+			return errors.New(msg("config.toml_null"))
+		default:
+			writeJSONString(buf, node.Value)
+		}
+	}
+	return nil
+}
+
+// tomlBareKey 判断键在 TOML 中是否可以不加引号
+func tomlBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlKey 必要时为键加上引号，例如 forge_hosts 中带点的主机名
+func tomlKey(key string) string {
+	if tomlBareKey(key) {
+		return key
+	}
+	var buf bytes.Buffer
+	writeJSONString(&buf, key)
+	return buf.String()
+}
+
+// tomlComment 把 YAML 注释转换为 TOML 注释，两者都以 # 开头
+func tomlComment(comment string) string {
+	var out strings.Builder
+	for _, line := range strings.Split(comment, "\n") {
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
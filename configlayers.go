@@ -0,0 +1,401 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalConfigFile 开发者本地的配置文件，不应提交到仓库
+const LocalConfigFile = "ghc.local.yaml"
+
+// activeProfile 通过 --profile 选中的发布方案
+var activeProfile string
+
+// activePackage 通过 --package 选中的包
+var activePackage string
+
+// configOverrides 命令行中通过 --set key=value 指定的配置，优先级最高
+var configOverrides []string
+
+// configLayer 配置的一个来源，后面的层覆盖前面的层
+type configLayer struct {
+	Name string     // 来源名称，用于 --show-origin
+	Path string     // 来源为文件时的路径
+	Doc  *yaml.Node // 该层的配置文档
+}
+
+// LayeredConfig 合并后的配置以及每个配置项的来源
+type LayeredConfig struct {
+	Config  *Config
+	Doc     *yaml.Node        // 合并后的配置文档
+	Origins map[string]string // 点分路径到来源名称的映射
+	Layers  []configLayer
+}
+
+// globalConfigPath 用户级配置文件的路径：设置了 $XDG_CONFIG_HOME 时位于其中，否则为 ~/.config/ghc/config.yaml，
+// macOS 上也使用 ~/.config 而不是 ~/Library/Application Support；Windows 上位于 %AppData%\ghc\config.yaml
+func globalConfigPath() string {
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(dir, "ghc", "config.yaml")
+	}
+	// 与 XDG 规范一致，忽略相对路径
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "ghc", "config.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "ghc", "config.yaml")
+}
+
+// configFileForScope 返回 ghc config set/unset 写入的文件
+func configFileForScope(scope string) (string, error) {
+	switch scope {
+	case "global":
+		path := globalConfigPath()
+		if path == "" {
+			return "", errors.New(msg("config.no_user_dir"))
+		}
+		return path, nil
+	case "local":
+		return LocalConfigFile, nil
+	default:
+		return projectConfigFile(), nil
+	}
+}
+
+// LoadLayeredConfig 按优先级从低到高合并全局配置、项目配置、本地配置、GHC_* 环境变量和 --set 参数
+// 每一层都会单独按模式校验，文件中的错误带有行列号
+func LoadLayeredConfig() (*LayeredConfig, error) {
+	project := findProjectConfig()
+	if project == "" {
+		return nil, fmt.Errorf(msg("config.not_found"), projectConfigFile())
+	}
+
+	var layers []configLayer
+	var errs ConfigErrors
+	for _, path := range []string{globalConfigPath(), project, LocalConfigFile} {
+		if path == "" || (path != project && !fileExists(path)) {
+			continue
+		}
+		debugf(msg("verbose.config_layer"), path)
+		doc, err := loadConfigDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		migrated, err := migrateConfigFile(path, doc)
+		if err != nil {
+			return nil, err
+		}
+		if migrated != nil {
+			printConfigMigration(migrated)
+		}
+		errs = append(errs, validateConfigDocument(path, doc)...)
+		layers = append(layers, configLayer{Name: path, Path: path, Doc: doc})
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf(msg("config.invalid_file"), errs)
+	}
+
+	merged := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	origins := make(map[string]string)
+	for _, layer := range layers {
+		overlayConfigNode(merged.Content[0], layer.Doc.Content[0], "", layer.Name, origins)
+	}
+
+	// 选中的方案覆盖所有配置文件，环境变量和 --set 参数仍然优先
+	if activeProfile != "" {
+		layer, err := profileConfigLayer(merged, activeProfile)
+		if err != nil {
+			return nil, err
+		}
+		overlayConfigNode(merged.Content[0], layer.Doc.Content[0], "", layer.Name, origins)
+		layers = append(layers, layer)
+	}
+
+	// 选中的包覆盖顶层的版本、标签前缀和构建命令
+	if activePackage != "" {
+		layer, err := packageConfigLayer(merged, activePackage)
+		if err != nil {
+			return nil, err
+		}
+		overlayConfigNode(merged.Content[0], layer.Doc.Content[0], "", layer.Name, origins)
+		layers = append(layers, layer)
+	}
+
+	for _, layer := range []func() (configLayer, error){envConfigLayer, flagConfigLayer} {
+		l, err := layer()
+		if err != nil {
+			return nil, err
+		}
+		if len(l.Doc.Content[0].Content) == 0 {
+			continue
+		}
+		if errs := validateConfigDocument(l.Name, l.Doc); len(errs) > 0 {
+			return nil, fmt.Errorf(msg("config.invalid"), errs)
+		}
+		overlayConfigNode(merged.Content[0], l.Doc.Content[0], "", l.Name, origins)
+		layers = append(layers, l)
+	}
+
+	var config Config
+	if err := merged.Decode(&config); err != nil {
+		return nil, fmt.Errorf(msg("config.decode_failed"), err)
+	}
+
+	return &LayeredConfig{Config: &config, Doc: merged, Origins: origins, Layers: layers}, nil
+}
+
+// profileConfigLayer 从合并后的配置中取出指定方案，方案不存在时列出可选的方案
+func profileConfigLayer(merged *yaml.Node, name string) (configLayer, error) {
+	profiles, _ := mappingValue(merged.Content[0], "profiles")
+	var profile *yaml.Node
+	var names []string
+	if profiles != nil {
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			names = append(names, profiles.Content[i].Value)
+		}
+		profile, _ = mappingValue(profiles, name)
+	}
+	if profile == nil {
+		if len(names) == 0 {
+			return configLayer{}, fmt.Errorf(msg("profile.undefined"), name)
+		}
+		return configLayer{}, fmt.Errorf(msg("profile.undefined_choices"), name, strings.Join(names, msg("common.list_separator")))
+	}
+
+	body := copyConfigNode(profile)
+	if body.Kind != yaml.MappingNode {
+		body = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	return configLayer{Name: "profile:" + name, Doc: &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{body}}}, nil
+}
+
+// packageConfigLayer 从合并后的配置中取出指定包的版本、标签前缀和构建命令，
+// 没有设置 tag_prefix 时使用 <path>/v。版本总是来自包，保存配置时写入 packages.<name>.version
+func packageConfigLayer(merged *yaml.Node, name string) (configLayer, error) {
+	packages, _ := mappingValue(merged.Content[0], "packages")
+	var pkg *yaml.Node
+	var names []string
+	if packages != nil {
+		for i := 0; i+1 < len(packages.Content); i += 2 {
+			names = append(names, packages.Content[i].Value)
+		}
+		pkg, _ = mappingValue(packages, name)
+	}
+	if pkg == nil {
+		if len(names) == 0 {
+			return configLayer{}, fmt.Errorf(msg("package.undefined"), name)
+		}
+		return configLayer{}, fmt.Errorf(msg("package.undefined_choices"), name, strings.Join(names, msg("common.list_separator")))
+	}
+
+	path := ""
+	if value, _ := mappingValue(pkg, "path"); value != nil {
+		path = value.Value
+	}
+	defaults := map[string]string{"version": "", "tag_prefix": packageTagPrefix(path)}
+	body := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range []string{"version", "tag_prefix", "build_command"} {
+		value, _ := mappingValue(pkg, key)
+		if value == nil {
+			fallback, ok := defaults[key]
+			if !ok {
+				continue
+			}
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fallback}
+		}
+		body.Content = append(body.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, copyConfigNode(value))
+	}
+	return configLayer{Name: "package:" + name, Doc: &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{body}}}, nil
+}
+
+// overlayConfigNode 把 overlay 深度合并到 base 中：对象按键合并，标量和数组整体替换
+func overlayConfigNode(base, overlay *yaml.Node, path, origin string, origins map[string]string) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		keyPath := joinSchemaPath(path, key.Value)
+
+		current, _ := mappingValue(base, key.Value)
+		if current != nil && current.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			overlayConfigNode(current, value, keyPath, origin, origins)
+			continue
+		}
+
+		copied := copyConfigNode(value)
+		if current != nil {
+			*current = *copied
+		} else {
+			base.Content = append(base.Content, copyConfigNode(key), copied)
+		}
+		for p := range origins {
+			if p == keyPath || strings.HasPrefix(p, keyPath+".") || strings.HasPrefix(p, keyPath+"[") {
+				delete(origins, p)
+			}
+		}
+		flattenConfigNode(value, keyPath, func(p string, _ *yaml.Node) {
+			origins[p] = origin
+		})
+	}
+}
+
+// copyConfigNode 深拷贝节点，避免合并时修改各层的文档
+func copyConfigNode(node *yaml.Node) *yaml.Node {
+	copied := *node
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return copyConfigNode(node.Alias)
+	}
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = copyConfigNode(child)
+	}
+	return &copied
+}
+
+// configEnvName 配置项对应的环境变量名，例如 pre_build.timeout 对应 GHC_PRE_BUILD_TIMEOUT
+func configEnvName(path string) string {
+	return "GHC_" + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// envConfigLayer 从 GHC_* 环境变量读取配置，只支持对象中的标量配置项
+func envConfigLayer() (configLayer, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	var walk func(schema *schemaNode, path string) error
+	walk = func(schema *schemaNode, path string) error {
+		for _, field := range schema.Fields {
+			fieldPath := joinSchemaPath(path, field.Name)
+			switch field.Node.Type {
+			case "object":
+				if err := walk(field.Node, fieldPath); err != nil {
+					return err
+				}
+			case "string", "integer", "boolean":
+				name := configEnvName(fieldPath)
+				raw, ok := os.LookupEnv(name)
+				if !ok {
+					continue
+				}
+				value, err := coerceConfigValue(field.Node, raw)
+				if err != nil {
+					return fmt.Errorf(msg("config.env_invalid"), name, err)
+				}
+				segments, _, _ := parseConfigPath(fieldPath)
+				if err := setConfigNode(doc, segments, value); err != nil {
+					return fmt.Errorf(msg("config.env_invalid"), name, err)
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(configSchema, ""); err != nil {
+		return configLayer{}, err
+	}
+	return configLayer{Name: "env", Doc: doc}, nil
+}
+
+// flagConfigLayer 从 --set key=value 参数读取配置
+func flagConfigLayer() (configLayer, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	for _, override := range configOverrides {
+		key, raw, ok := strings.Cut(override, "=")
+		if !ok {
+			return configLayer{}, fmt.Errorf(msg("config.set_flag_invalid"), override)
+		}
+		segments, schema, err := parseConfigPath(key)
+		if err != nil {
+			return configLayer{}, fmt.Errorf("--set %s: %w", override, err)
+		}
+		value, err := coerceConfigValue(schema, raw)
+		if err != nil {
+			return configLayer{}, fmt.Errorf("--set %s: %w", key, err)
+		}
+		if err := setConfigNode(doc, segments, value); err != nil {
+			return configLayer{}, fmt.Errorf("--set %s: %w", key, err)
+		}
+	}
+	return configLayer{Name: "--set", Doc: doc}, nil
+}
+
+// applyConfigDelta 把 desired 相对于合并后配置 effective 的改动写入 target 文档
+// 只写入发生变化的配置项，来自其他层的值（例如全局配置中的 token）不会被写入项目配置；
+// route 决定每个配置项写入的位置，例如来自发布方案的配置项写回方案中
+func applyConfigDelta(target, effective, desired *yaml.Node, segments []configPathSegment, route func([]configPathSegment) []configPathSegment) error {
+	if sameNodeValue(effective, desired) {
+		return nil
+	}
+
+	if effective.Kind == yaml.MappingNode && desired.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(desired.Content); i += 2 {
+			key, value := desired.Content[i], desired.Content[i+1]
+			keySegments := append(append([]configPathSegment{}, segments...), configPathSegment{Key: key.Value, Index: -1})
+			current, _ := mappingValue(effective, key.Value)
+			if current == nil {
+				if isZeroNode(value) {
+					continue
+				}
+				current = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			}
+			if err := applyConfigDelta(target, current, value, keySegments, route); err != nil {
+				return err
+			}
+		}
+		// desired 中省略（omitempty）的键表示被清空
+		for i := 0; i+1 < len(effective.Content); i += 2 {
+			if value, _ := mappingValue(desired, effective.Content[i].Value); value == nil && !isZeroNode(effective.Content[i+1]) {
+				keySegments := append(append([]configPathSegment{}, segments...), configPathSegment{Key: effective.Content[i].Value, Index: -1})
+				unsetConfigNode(target, route(keySegments))
+			}
+		}
+		return nil
+	}
+
+	segments = route(segments)
+	if existing := lookupConfigNode(target, segments); existing != nil {
+		mergeConfigNode(existing, desired)
+		return nil
+	}
+	return setConfigNode(target, segments, desired)
+}
+
+// formatConfigPath 把路径片段格式化为点分路径
+func formatConfigPath(segments []configPathSegment) string {
+	path := ""
+	for _, segment := range segments {
+		if segment.Index >= 0 {
+			path += fmt.Sprintf("[%d]", segment.Index)
+		} else {
+			path = joinSchemaPath(path, segment.Key)
+		}
+	}
+	return path
+}
+
+// layerRoute 把来自选中方案的配置项改为写入 profiles.<name> 下，来自选中包的配置项写入 packages.<name> 下，
+// 其余配置项写入顶层
+func layerRoute(origins map[string]string) func([]configPathSegment) []configPathSegment {
+	return func(segments []configPathSegment) []configPathSegment {
+		path := formatConfigPath(segments)
+		fromLayer := func(origin string) bool {
+			return origins[path] == origin || origins[path+"[0]"] == origin
+		}
+		switch {
+		case activePackage != "" && fromLayer("package:"+activePackage):
+			return append([]configPathSegment{{Key: "packages", Index: -1}, {Key: activePackage, Index: -1}}, segments...)
+		case activeProfile != "" && fromLayer("profile:"+activeProfile):
+			return append([]configPathSegment{{Key: "profiles", Index: -1}, {Key: activeProfile, Index: -1}}, segments...)
+		}
+		return segments
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestGlobalConfigPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 上使用 %AppData%")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	if got, want := globalConfigPath(), filepath.Join(home, ".config", "ghc", "config.yaml"); got != want {
+		t.Errorf("without XDG_CONFIG_HOME: globalConfigPath() = %q, want %q", got, want)
+	}
+
+	xdg := filepath.Join(home, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if got, want := globalConfigPath(), filepath.Join(xdg, "ghc", "config.yaml"); got != want {
+		t.Errorf("with XDG_CONFIG_HOME: globalConfigPath() = %q, want %q", got, want)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "relative")
+	if got, want := globalConfigPath(), filepath.Join(home, ".config", "ghc", "config.yaml"); got != want {
+		t.Errorf("relative XDG_CONFIG_HOME: globalConfigPath() = %q, want %q", got, want)
+	}
+}
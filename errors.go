@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 错误类别，可以用 errors.Is(err, ErrBuild) 判断错误属于哪一类
+var (
+	ErrUsage   error = errorCategory("error.usage")
+	ErrConfig  error = errorCategory("error.config")
+	ErrGit     error = errorCategory("error.git")
+	ErrBuild   error = errorCategory("error.build")
+	ErrNetwork error = errorCategory("error.network")
+	ErrPartial error = errorCategory("error.partial")
+)
+
+// errorCategory 错误类别，值为类别名称在 messages 中的键，输出时按当前语言翻译
+type errorCategory string
+
+func (c errorCategory) Error() string { return msg(string(c)) }
+
+// errorCategories 错误类别对应的错误码
+var errorCategories = map[error]string{
+	ErrUsage:   ErrCodeUsage,
+	ErrConfig:  ErrCodeConfig,
+	ErrGit:     ErrCodeGit,
+	ErrBuild:   ErrCodeBuild,
+	ErrNetwork: ErrCodeNetwork,
+	ErrPartial: ErrCodePartial,
+}
+
+// categoryError 带有类别的错误，main 根据类别决定退出码
+type categoryError struct {
+	category error
+	err      error
+}
+
+func (e *categoryError) Error() string { return e.err.Error() }
+
+func (e *categoryError) Unwrap() error { return e.err }
+
+// Is 让 errors.Is 能够匹配错误类别
+func (e *categoryError) Is(target error) bool { return target == e.category }
+
+// categorize 按 format 生成错误并标记类别；被包装的错误已经带有类别时保留原来的类别，
+// 例如推送失败在底层标记为网络错误，上层包装时不会被改为 git 错误
+func categorize(category error, format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	var categorized *categoryError
+	if errors.As(err, &categorized) {
+		return err
+	}
+	return &categoryError{category: category, err: err}
+}
+
+func usageError(format string, args ...interface{}) error {
+	return categorize(ErrUsage, format, args...)
+}
+
+func configError(format string, args ...interface{}) error {
+	return categorize(ErrConfig, format, args...)
+}
+
+func gitError(format string, args ...interface{}) error {
+	return categorize(ErrGit, format, args...)
+}
+
+func buildError(format string, args ...interface{}) error {
+	return categorize(ErrBuild, format, args...)
+}
+
+func networkError(format string, args ...interface{}) error {
+	return categorize(ErrNetwork, format, args...)
+}
+
+func partialError(format string, args ...interface{}) error {
+	return categorize(ErrPartial, format, args...)
+}
+
+// errorCode 返回错误对应的错误码，没有类别的错误按其类型判断
+func errorCode(err error) string {
+	var categorized *categoryError
+	if errors.As(err, &categorized) {
+		return errorCategories[categorized.category]
+	}
+
+	var busy *LockBusyError
+	var configErr *ConfigError
+	var configErrs ConfigErrors
+	var corrupt *RepoLockCorruptError
+	switch {
+	case errors.As(err, &busy):
+		return ErrCodeLockBusy
+	case errors.As(err, &configErr), errors.As(err, &configErrs), errors.As(err, &corrupt):
+		return ErrCodeConfig
+	}
+	return ErrCodeFailure
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 支持的代码托管平台
+const (
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeGitea     = "gitea"
+	ForgeBitbucket = "bitbucket"
+)
+
+// defaultForgeHosts 常见公共托管平台的主机名映射
+var defaultForgeHosts = map[string]string{
+	"github.com":    ForgeGitHub,
+	"gitlab.com":    ForgeGitLab,
+	"gitea.com":     ForgeGitea,
+	"codeberg.org":  ForgeGitea,
+	"bitbucket.org": ForgeBitbucket,
+}
+
+// DetectForge 确定仓库所在的托管平台
+// 优先使用配置中的 forge，其次是 forge_hosts 映射和内置的公共平台，
+// 最后根据主机名中的关键字推断；本地仓库或无法识别时返回空字符串
+func DetectForge(config *Config, repo RepoURL) (string, error) {
+	if config != nil && config.Forge != "" {
+		forge := strings.ToLower(config.Forge)
+		if !isKnownForge(forge) {
+			return "", fmt.Errorf(msg("forge.unsupported"), config.Forge)
+		}
+		return forge, nil
+	}
+
+	if repo.IsLocal() {
+		return "", nil
+	}
+
+	host := strings.ToLower(repo.Host)
+	if config != nil {
+		for pattern, forge := range config.ForgeHosts {
+			if strings.ToLower(pattern) == host {
+				forge = strings.ToLower(forge)
+				if !isKnownForge(forge) {
+					return "", fmt.Errorf(msg("forge.invalid_host_forge"), pattern, forge)
+				}
+				return forge, nil
+			}
+		}
+	}
+	if forge, ok := defaultForgeHosts[host]; ok {
+		return forge, nil
+	}
+
+	for _, forge := range []string{ForgeGitHub, ForgeGitLab, ForgeGitea, ForgeBitbucket} {
+		if strings.Contains(host, forge) {
+			return forge, nil
+		}
+	}
+	return "", nil
+}
+
+// isKnownForge 判断是否为支持的托管平台
+func isKnownForge(forge string) bool {
+	switch forge {
+	case ForgeGitHub, ForgeGitLab, ForgeGitea, ForgeBitbucket:
+		return true
+	}
+	return false
+}
+
+// Release 要创建的发布
+type Release struct {
+	TagName    string
+	Name       string
+	Body       string
+	Target     string // 标签不存在时用于创建标签的提交或分支
+	Draft      bool
+	Prerelease bool
+}
+
+// ReleaseInfo 托管平台返回的发布信息
+type ReleaseInfo struct {
+	ID        string
+	TagName   string
+	URL       string // 发布页面地址
+	UploadURL string // 上传附件的地址（由平台决定是否使用）
+}
+
+// ReleaseProvider 托管平台的发布接口，每个平台提供各自的实现
+type ReleaseProvider interface {
+	// Name 返回托管平台名称
+	Name() string
+	// GetRelease 查询标签对应的发布，发布不存在时返回 nil
+	GetRelease(ctx context.Context, tagName string) (*ReleaseInfo, error)
+	// CreateRelease 为已推送的标签创建发布
+	CreateRelease(ctx context.Context, release Release) (*ReleaseInfo, error)
+	// UploadAsset 把构建产物上传到发布
+	UploadAsset(ctx context.Context, release *ReleaseInfo, path string) error
+}
+
+// NewReleaseProvider 根据托管平台创建发布接口
+// apiBase 为空时根据仓库地址推导平台的 API 地址
+func NewReleaseProvider(forge string, repo RepoURL, apiBase, token string) (ReleaseProvider, error) {
+	if repo.IsLocal() {
+		return nil, errors.New(msg("forge.local_repo"))
+	}
+	if repo.Owner() == "" {
+		return nil, fmt.Errorf(msg("forge.missing_owner"), repo)
+	}
+
+	client := &forgeClient{
+		http:  &http.Client{Timeout: 5 * time.Minute},
+		token: token,
+	}
+
+	switch forge {
+	case ForgeGitHub:
+		return newGitHubProvider(client, repo, apiBase), nil
+	case ForgeGitLab:
+		return newGitLabProvider(client, repo, apiBase), nil
+	case ForgeGitea:
+		return newGiteaProvider(client, repo, apiBase), nil
+	case "":
+		return nil, fmt.Errorf(msg("forge.unknown_host"), repo.Host)
+	default:
+		return nil, fmt.Errorf(msg("forge.no_releases"), forge)
+	}
+}
+
+// forgeClient 托管平台 API 的 HTTP 客户端
+type forgeClient struct {
+	http  *http.Client
+	token string
+}
+
+// forgeRequest 描述一次 API 请求
+type forgeRequest struct {
+	Method        string
+	URL           string
+	Header        http.Header
+	Body          io.Reader
+	ContentType   string
+	ContentLength int64       // 流式请求体的长度，部分平台上传附件时要求设置
+	JSON          interface{} // 非空时作为 JSON 请求体
+}
+
+// do 发送请求，响应为 JSON 时解析到 out 中，非 2xx 响应返回包含响应内容的错误
+func (c *forgeClient) do(ctx context.Context, req forgeRequest, out interface{}) error {
+	body := req.Body
+	contentType := req.ContentType
+	if req.JSON != nil {
+		data, err := json.Marshal(req.JSON)
+		if err != nil {
+			return fmt.Errorf(msg("forge.encode_failed"), err)
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
+	if err != nil {
+		return fmt.Errorf(msg("forge.new_request_failed"), err)
+	}
+	for key, values := range req.Header {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if req.ContentLength > 0 {
+		httpReq.ContentLength = req.ContentLength
+	}
+	if httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return networkError(msg("forge.request_failed"), req.Method, req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return networkError(msg("forge.read_failed"), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return networkError("%w", &forgeStatusError{
+			Method:     req.Method,
+			URL:        req.URL,
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Body:       truncate(strings.TrimSpace(string(data)), 300),
+		})
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf(msg("forge.decode_failed"), err)
+		}
+	}
+	return nil
+}
+
+// forgeStatusError 托管平台返回的非 2xx 响应
+type forgeStatusError struct {
+	Method     string
+	URL        string
+	Status     string
+	StatusCode int
+	Body       string
+}
+
+func (e *forgeStatusError) Error() string {
+	return msg("forge.http_error", e.Method, e.URL, e.Status, e.Body)
+}
+
+// isForgeNotFound 判断错误是否为托管平台返回的 404
+func isForgeNotFound(err error) bool {
+	var statusErr *forgeStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// truncate 截断过长的文本
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
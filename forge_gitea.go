@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// giteaProvider Gitea（包括 Forgejo、Codeberg）的发布接口实现
+type giteaProvider struct {
+	client  *forgeClient
+	repo    RepoURL
+	apiBase string
+}
+
+// newGiteaProvider 创建 Gitea 发布接口，默认使用 <host>/api/v1
+func newGiteaProvider(client *forgeClient, repo RepoURL, apiBase string) *giteaProvider {
+	if apiBase == "" {
+		apiBase = repo.WebURL() + "/api/v1"
+	}
+	return &giteaProvider{client: client, repo: repo, apiBase: strings.TrimSuffix(apiBase, "/")}
+}
+
+// Name 返回托管平台名称
+func (p *giteaProvider) Name() string {
+	return ForgeGitea
+}
+
+// header 返回 Gitea API 的公共请求头
+func (p *giteaProvider) header() http.Header {
+	header := http.Header{}
+	if p.client.token != "" {
+		header.Set("Authorization", "token "+p.client.token)
+	}
+	return header
+}
+
+// repoURL 返回仓库 API 地址
+func (p *giteaProvider) repoURL() string {
+	return fmt.Sprintf("%s/repos/%s/%s", p.apiBase, url.PathEscape(p.repo.Owner()), url.PathEscape(p.repo.Name()))
+}
+
+// giteaRelease Gitea 返回的发布
+type giteaRelease struct {
+	ID      int64  `json:"id"`
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+func (r *giteaRelease) info() *ReleaseInfo {
+	return &ReleaseInfo{
+		ID:      strconv.FormatInt(r.ID, 10),
+		TagName: r.TagName,
+		URL:     r.HTMLURL,
+	}
+}
+
+// GetRelease 通过 GET /repos/{owner}/{repo}/releases/tags/{tag} 查询发布
+func (p *giteaProvider) GetRelease(ctx context.Context, tagName string) (*ReleaseInfo, error) {
+	var resp giteaRelease
+	err := p.client.do(ctx, forgeRequest{
+		Method: http.MethodGet,
+		URL:    p.repoURL() + "/releases/tags/" + url.PathEscape(tagName),
+		Header: p.header(),
+	}, &resp)
+	if isForgeNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(msg("forge.get_release_failed"), "Gitea", err)
+	}
+	return resp.info(), nil
+}
+
+// CreateRelease 通过 POST /repos/{owner}/{repo}/releases 创建发布
+func (p *giteaProvider) CreateRelease(ctx context.Context, release Release) (*ReleaseInfo, error) {
+	payload := map[string]interface{}{
+		"tag_name":   release.TagName,
+		"name":       release.Name,
+		"body":       release.Body,
+		"draft":      release.Draft,
+		"prerelease": release.Prerelease,
+	}
+	if release.Target != "" {
+		payload["target_commitish"] = release.Target
+	}
+
+	var resp giteaRelease
+	err := p.client.do(ctx, forgeRequest{
+		Method: http.MethodPost,
+		URL:    p.repoURL() + "/releases",
+		Header: p.header(),
+		JSON:   payload,
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf(msg("forge.create_release_failed"), "Gitea", err)
+	}
+	return resp.info(), nil
+}
+
+// UploadAsset 通过 POST /repos/{owner}/{repo}/releases/{id}/assets 上传发布附件
+func (p *giteaProvider) UploadAsset(ctx context.Context, release *ReleaseInfo, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf(msg("forge.open_asset_failed"), err)
+	}
+	defer file.Close()
+
+	fileName := filepath.Base(path)
+
+	// 以 multipart 表单流式上传，避免把大文件读入内存
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		part, err := form.CreateFormFile("attachment", fileName)
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	err = p.client.do(ctx, forgeRequest{
+		Method:      http.MethodPost,
+		URL:         fmt.Sprintf("%s/releases/%s/assets?name=%s", p.repoURL(), url.PathEscape(release.ID), url.QueryEscape(fileName)),
+		Header:      p.header(),
+		Body:        body,
+		ContentType: form.FormDataContentType(),
+	}, nil)
+	body.Close()
+	if err != nil {
+		return fmt.Errorf(msg("forge.upload_failed"), fileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gitHubProvider GitHub（包括 GitHub Enterprise）的发布接口实现
+type gitHubProvider struct {
+	client  *forgeClient
+	repo    RepoURL
+	apiBase string
+}
+
+// newGitHubProvider 创建 GitHub 发布接口，github.com 使用 api.github.com，其他主机视为 GitHub Enterprise
+func newGitHubProvider(client *forgeClient, repo RepoURL, apiBase string) *gitHubProvider {
+	if apiBase == "" {
+		if strings.EqualFold(repo.Host, "github.com") {
+			apiBase = "https://api.github.com"
+		} else {
+			apiBase = repo.WebURL() + "/api/v3"
+		}
+	}
+	return &gitHubProvider{client: client, repo: repo, apiBase: strings.TrimSuffix(apiBase, "/")}
+}
+
+// Name 返回托管平台名称
+func (p *gitHubProvider) Name() string {
+	return ForgeGitHub
+}
+
+// header 返回 GitHub API 的公共请求头
+func (p *gitHubProvider) header() http.Header {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if p.client.token != "" {
+		header.Set("Authorization", "Bearer "+p.client.token)
+	}
+	return header
+}
+
+// repoURL 返回仓库 API 地址
+func (p *gitHubProvider) repoURL() string {
+	return fmt.Sprintf("%s/repos/%s/%s", p.apiBase, url.PathEscape(p.repo.Owner()), url.PathEscape(p.repo.Name()))
+}
+
+// gitHubRelease GitHub 返回的发布
+type gitHubRelease struct {
+	ID        int64  `json:"id"`
+	TagName   string `json:"tag_name"`
+	HTMLURL   string `json:"html_url"`
+	UploadURL string `json:"upload_url"`
+}
+
+func (r *gitHubRelease) info() *ReleaseInfo {
+	return &ReleaseInfo{
+		ID:        strconv.FormatInt(r.ID, 10),
+		TagName:   r.TagName,
+		URL:       r.HTMLURL,
+		UploadURL: r.UploadURL,
+	}
+}
+
+// GetRelease 通过 GET /repos/{owner}/{repo}/releases/tags/{tag} 查询发布
+func (p *gitHubProvider) GetRelease(ctx context.Context, tagName string) (*ReleaseInfo, error) {
+	var resp gitHubRelease
+	err := p.client.do(ctx, forgeRequest{
+		Method: http.MethodGet,
+		URL:    p.repoURL() + "/releases/tags/" + url.PathEscape(tagName),
+		Header: p.header(),
+	}, &resp)
+	if isForgeNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(msg("forge.get_release_failed"), "GitHub", err)
+	}
+	return resp.info(), nil
+}
+
+// CreateRelease 通过 POST /repos/{owner}/{repo}/releases 创建发布
+func (p *gitHubProvider) CreateRelease(ctx context.Context, release Release) (*ReleaseInfo, error) {
+	payload := map[string]interface{}{
+		"tag_name":   release.TagName,
+		"name":       release.Name,
+		"body":       release.Body,
+		"draft":      release.Draft,
+		"prerelease": release.Prerelease,
+	}
+	if release.Target != "" {
+		payload["target_commitish"] = release.Target
+	}
+
+	var resp gitHubRelease
+	err := p.client.do(ctx, forgeRequest{
+		Method: http.MethodPost,
+		URL:    p.repoURL() + "/releases",
+		Header: p.header(),
+		JSON:   payload,
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf(msg("forge.create_release_failed"), "GitHub", err)
+	}
+	return resp.info(), nil
+}
+
+// UploadAsset 上传发布附件，upload_url 形如 https://uploads.github.com/.../assets{?name,label}
+func (p *gitHubProvider) UploadAsset(ctx context.Context, release *ReleaseInfo, path string) error {
+	if release.UploadURL == "" {
+		return fmt.Errorf(msg("forge.missing_upload_url"), release.TagName)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf(msg("forge.open_asset_failed"), err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf(msg("forge.stat_asset_failed"), err)
+	}
+
+	uploadURL := release.UploadURL
+	if i := strings.Index(uploadURL, "{"); i >= 0 {
+		uploadURL = uploadURL[:i]
+	}
+	uploadURL += "?name=" + url.QueryEscape(filepath.Base(path))
+
+	err = p.client.do(ctx, forgeRequest{
+		Method:        http.MethodPost,
+		URL:           uploadURL,
+		Header:        p.header(),
+		Body:          file,
+		ContentType:   "application/octet-stream",
+		ContentLength: info.Size(),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf(msg("forge.upload_failed"), filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitLabProvider GitLab（包括自建实例）的发布接口实现
+type gitLabProvider struct {
+	client  *forgeClient
+	repo    RepoURL
+	apiBase string
+}
+
+// newGitLabProvider 创建 GitLab 发布接口，默认使用 <host>/api/v4
+func newGitLabProvider(client *forgeClient, repo RepoURL, apiBase string) *gitLabProvider {
+	if apiBase == "" {
+		apiBase = repo.WebURL() + "/api/v4"
+	}
+	return &gitLabProvider{client: client, repo: repo, apiBase: strings.TrimSuffix(apiBase, "/")}
+}
+
+// Name 返回托管平台名称
+func (p *gitLabProvider) Name() string {
+	return ForgeGitLab
+}
+
+// header 返回 GitLab API 的公共请求头
+func (p *gitLabProvider) header() http.Header {
+	header := http.Header{}
+	if p.client.token != "" {
+		header.Set("PRIVATE-TOKEN", p.client.token)
+	}
+	return header
+}
+
+// projectURL 返回项目 API 地址，项目路径（可能包含子群组）整体编码为项目 ID
+func (p *gitLabProvider) projectURL() string {
+	return fmt.Sprintf("%s/projects/%s", p.apiBase, url.PathEscape(p.repo.Path))
+}
+
+// gitLabRelease GitLab 返回的发布，GitLab 以标签名标识发布
+type gitLabRelease struct {
+	TagName string `json:"tag_name"`
+	Links   struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+func (r *gitLabRelease) info() *ReleaseInfo {
+	return &ReleaseInfo{
+		ID:      r.TagName,
+		TagName: r.TagName,
+		URL:     r.Links.Self,
+	}
+}
+
+// GetRelease 通过 GET /projects/:id/releases/:tag_name 查询发布
+func (p *gitLabProvider) GetRelease(ctx context.Context, tagName string) (*ReleaseInfo, error) {
+	var resp gitLabRelease
+	err := p.client.do(ctx, forgeRequest{
+		Method: http.MethodGet,
+		URL:    p.projectURL() + "/releases/" + url.PathEscape(tagName),
+		Header: p.header(),
+	}, &resp)
+	if isForgeNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(msg("forge.get_release_failed"), "GitLab", err)
+	}
+	return resp.info(), nil
+}
+
+// CreateRelease 通过 POST /projects/:id/releases 创建发布
+// GitLab 没有草稿和预发布的概念，Draft 和 Prerelease 会被忽略
+func (p *gitLabProvider) CreateRelease(ctx context.Context, release Release) (*ReleaseInfo, error) {
+	payload := map[string]interface{}{
+		"tag_name":    release.TagName,
+		"name":        release.Name,
+		"description": release.Body,
+	}
+	if release.Target != "" {
+		payload["ref"] = release.Target
+	}
+
+	var resp gitLabRelease
+	err := p.client.do(ctx, forgeRequest{
+		Method: http.MethodPost,
+		URL:    p.projectURL() + "/releases",
+		Header: p.header(),
+		JSON:   payload,
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf(msg("forge.create_release_failed"), "GitLab", err)
+	}
+	return resp.info(), nil
+}
+
+// UploadAsset 把附件上传到项目的通用软件包仓库，再作为链接添加到发布
+// 软件包名为仓库名，版本为标签名
+func (p *gitLabProvider) UploadAsset(ctx context.Context, release *ReleaseInfo, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf(msg("forge.open_asset_failed"), err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf(msg("forge.stat_asset_failed"), err)
+	}
+
+	fileName := filepath.Base(path)
+	packageURL := fmt.Sprintf("%s/packages/generic/%s/%s/%s", p.projectURL(),
+		url.PathEscape(p.repo.Name()), url.PathEscape(release.TagName), url.PathEscape(fileName))
+
+	err = p.client.do(ctx, forgeRequest{
+		Method:        http.MethodPut,
+		URL:           packageURL,
+		Header:        p.header(),
+		Body:          file,
+		ContentType:   "application/octet-stream",
+		ContentLength: info.Size(),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf(msg("forge.upload_package_failed"), fileName, err)
+	}
+
+	err = p.client.do(ctx, forgeRequest{
+		Method: http.MethodPost,
+		URL:    fmt.Sprintf("%s/releases/%s/assets/links", p.projectURL(), url.PathEscape(release.TagName)),
+		Header: p.header(),
+		JSON: map[string]string{
+			"name":      fileName,
+			"url":       packageURL,
+			"link_type": "package",
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf(msg("forge.link_asset_failed"), fileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeResponse 托管平台替身对某个请求返回的响应
+type fakeResponse struct {
+	Status int
+	Body   string
+}
+
+// recordedRequest 托管平台替身收到的请求
+type recordedRequest struct {
+	Method string
+	Path   string // 保留编码的路径，例如 /projects/group%2Frepo
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// fakeForge 记录收到的请求，并按 "METHOD 路径" 返回预设响应的托管平台替身，未预设的请求返回 404
+type fakeForge struct {
+	*httptest.Server
+	routes map[string]fakeResponse
+
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+func newFakeForge(t *testing.T, routes map[string]fakeResponse) *fakeForge {
+	t.Helper()
+	f := &fakeForge{routes: routes}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		f.requests = append(f.requests, recordedRequest{
+			Method: r.Method,
+			Path:   r.URL.EscapedPath(),
+			Query:  r.URL.RawQuery,
+			Header: r.Header.Clone(),
+			Body:   body,
+		})
+		f.mu.Unlock()
+
+		resp, ok := f.routes[r.Method+" "+r.URL.EscapedPath()]
+		if !ok {
+			resp = fakeResponse{Status: http.StatusNotFound, Body: `{"message":"404 Not Found"}`}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.Status)
+		io.WriteString(w, resp.Body)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// recorded 返回收到的所有请求
+func (f *fakeForge) recorded() []recordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]recordedRequest(nil), f.requests...)
+}
+
+// newTestProvider 创建指向替身的发布接口
+func newTestProvider(t *testing.T, forge, repoURL, apiBase string) ReleaseProvider {
+	t.Helper()
+	repo, err := ParseRepoURL(repoURL)
+	if err != nil {
+		t.Fatalf("ParseRepoURL(%q): %v", repoURL, err)
+	}
+	provider, err := NewReleaseProvider(forge, repo, apiBase, "secret")
+	if err != nil {
+		t.Fatalf("NewReleaseProvider(%q): %v", forge, err)
+	}
+	return provider
+}
+
+// decodeJSONBody 解析请求的 JSON 请求体
+func decodeJSONBody(t *testing.T, req recordedRequest) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		t.Fatalf("%s %s 的请求体不是 JSON: %v\n%s", req.Method, req.Path, err, req.Body)
+	}
+	return body
+}
+
+// providerCase 一个托管平台的发布接口在替身上的预期行为
+type providerCase struct {
+	forge      string
+	repoURL    string
+	apiPath    string // 替身上的 API 前缀
+	authHeader string
+	authValue  string
+	createPath string
+	getPath    string // 查询标签 v1.2.0 的路径
+	response   string // 创建和查询发布时替身返回的内容
+	want       ReleaseInfo
+	wantBody   map[string]interface{} // 创建发布时的请求体
+}
+
+var providerCases = []providerCase{
+	{
+		forge:      ForgeGitHub,
+		repoURL:    "https://github.com/owner/repo.git",
+		apiPath:    "/api/v3",
+		authHeader: "Authorization",
+		authValue:  "Bearer secret",
+		createPath: "/api/v3/repos/owner/repo/releases",
+		getPath:    "/api/v3/repos/owner/repo/releases/tags/v1.2.0",
+		response:   `{"id":42,"tag_name":"v1.2.0","html_url":"https://github.com/owner/repo/releases/tag/v1.2.0","upload_url":"https://uploads.github.com/repos/owner/repo/releases/42/assets{?name,label}"}`,
+		want: ReleaseInfo{
+			ID:        "42",
+			TagName:   "v1.2.0",
+			URL:       "https://github.com/owner/repo/releases/tag/v1.2.0",
+			UploadURL: "https://uploads.github.com/repos/owner/repo/releases/42/assets{?name,label}",
+		},
+		wantBody: map[string]interface{}{
+			"tag_name":         "v1.2.0",
+			"name":             "Release 1.2.0",
+			"body":             "notes",
+			"draft":            true,
+			"prerelease":       false,
+			"target_commitish": "main",
+		},
+	},
+	{
+		forge:      ForgeGitLab,
+		repoURL:    "git@gitlab.example.com:group/sub/repo.git",
+		apiPath:    "/api/v4",
+		authHeader: "Private-Token",
+		authValue:  "secret",
+		createPath: "/api/v4/projects/group%2Fsub%2Frepo/releases",
+		getPath:    "/api/v4/projects/group%2Fsub%2Frepo/releases/v1.2.0",
+		response:   `{"tag_name":"v1.2.0","_links":{"self":"https://gitlab.example.com/group/sub/repo/-/releases/v1.2.0"}}`,
+		want: ReleaseInfo{
+			ID:      "v1.2.0",
+			TagName: "v1.2.0",
+			URL:     "https://gitlab.example.com/group/sub/repo/-/releases/v1.2.0",
+		},
+		wantBody: map[string]interface{}{
+			"tag_name":    "v1.2.0",
+			"name":        "Release 1.2.0",
+			"description": "notes",
+			"ref":         "main",
+		},
+	},
+	{
+		forge:      ForgeGitea,
+		repoURL:    "https://codeberg.org/owner/repo",
+		apiPath:    "/api/v1",
+		authHeader: "Authorization",
+		authValue:  "token secret",
+		createPath: "/api/v1/repos/owner/repo/releases",
+		getPath:    "/api/v1/repos/owner/repo/releases/tags/v1.2.0",
+		response:   `{"id":7,"tag_name":"v1.2.0","html_url":"https://codeberg.org/owner/repo/releases/tag/v1.2.0"}`,
+		want: ReleaseInfo{
+			ID:      "7",
+			TagName: "v1.2.0",
+			URL:     "https://codeberg.org/owner/repo/releases/tag/v1.2.0",
+		},
+		wantBody: map[string]interface{}{
+			"tag_name":         "v1.2.0",
+			"name":             "Release 1.2.0",
+			"body":             "notes",
+			"draft":            true,
+			"prerelease":       false,
+			"target_commitish": "main",
+		},
+	},
+}
+
+func TestReleaseProviderCreateRelease(t *testing.T) {
+	for _, tc := range providerCases {
+		t.Run(tc.forge, func(t *testing.T) {
+			fake := newFakeForge(t, map[string]fakeResponse{
+				"POST " + tc.createPath: {Status: http.StatusCreated, Body: tc.response},
+			})
+			provider := newTestProvider(t, tc.forge, tc.repoURL, fake.URL+tc.apiPath)
+
+			info, err := provider.CreateRelease(context.Background(), Release{
+				TagName: "v1.2.0",
+				Name:    "Release 1.2.0",
+				Body:    "notes",
+				Target:  "main",
+				Draft:   true,
+			})
+			if err != nil {
+				t.Fatalf("CreateRelease: %v", err)
+			}
+			if *info != tc.want {
+				t.Errorf("CreateRelease = %+v, want %+v", *info, tc.want)
+			}
+
+			requests := fake.recorded()
+			if len(requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(requests))
+			}
+			req := requests[0]
+			if req.Method != http.MethodPost || req.Path != tc.createPath {
+				t.Errorf("request = %s %s, want POST %s", req.Method, req.Path, tc.createPath)
+			}
+			if got := req.Header.Get(tc.authHeader); got != tc.authValue {
+				t.Errorf("%s = %q, want %q", tc.authHeader, got, tc.authValue)
+			}
+			if got := req.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if body := decodeJSONBody(t, req); !reflect.DeepEqual(body, tc.wantBody) {
+				t.Errorf("body = %v, want %v", body, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestReleaseProviderGetRelease(t *testing.T) {
+	for _, tc := range providerCases {
+		t.Run(tc.forge, func(t *testing.T) {
+			fake := newFakeForge(t, map[string]fakeResponse{
+				"GET " + tc.getPath: {Status: http.StatusOK, Body: tc.response},
+			})
+			provider := newTestProvider(t, tc.forge, tc.repoURL, fake.URL+tc.apiPath)
+
+			info, err := provider.GetRelease(context.Background(), "v1.2.0")
+			if err != nil {
+				t.Fatalf("GetRelease: %v", err)
+			}
+			if info == nil || *info != tc.want {
+				t.Errorf("GetRelease = %+v, want %+v", info, tc.want)
+			}
+
+			requests := fake.recorded()
+			if len(requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(requests))
+			}
+			if req := requests[0]; req.Method != http.MethodGet || req.Path != tc.getPath {
+				t.Errorf("request = %s %s, want GET %s", req.Method, req.Path, tc.getPath)
+			}
+			if got := requests[0].Header.Get(tc.authHeader); got != tc.authValue {
+				t.Errorf("%s = %q, want %q", tc.authHeader, got, tc.authValue)
+			}
+
+			// 发布不存在时返回 nil 而不是错误
+			missing, err := provider.GetRelease(context.Background(), "v9.9.9")
+			if err != nil || missing != nil {
+				t.Errorf("GetRelease(missing) = %+v, %v, want nil, nil", missing, err)
+			}
+		})
+	}
+}
+
+func TestReleaseProviderErrorStatus(t *testing.T) {
+	for _, tc := range providerCases {
+		t.Run(tc.forge, func(t *testing.T) {
+			fake := newFakeForge(t, map[string]fakeResponse{
+				"POST " + tc.createPath: {Status: http.StatusUnprocessableEntity, Body: `{"message":"tag already has a release"}`},
+				"GET " + tc.getPath:     {Status: http.StatusUnauthorized, Body: `{"message":"bad credentials"}`},
+			})
+			provider := newTestProvider(t, tc.forge, tc.repoURL, fake.URL+tc.apiPath)
+
+			_, err := provider.CreateRelease(context.Background(), Release{TagName: "v1.2.0"})
+			if err == nil {
+				t.Fatal("CreateRelease succeeded on 422")
+			}
+			if !errors.Is(err, ErrNetwork) {
+				t.Errorf("CreateRelease error %v is not a network error", err)
+			}
+			var statusErr *forgeStatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("CreateRelease error %v does not carry status 422", err)
+			}
+			if !strings.Contains(err.Error(), "tag already has a release") {
+				t.Errorf("CreateRelease error %q does not include the response body", err)
+			}
+
+			// 只有 404 表示发布不存在，其他错误状态需要报告
+			info, err := provider.GetRelease(context.Background(), "v1.2.0")
+			if err == nil || info != nil {
+				t.Errorf("GetRelease on 401 = %+v, %v, want an error", info, err)
+			}
+		})
+	}
+}
+
+func TestNewReleaseProviderRejectsUnsupported(t *testing.T) {
+	local, _ := ParseRepoURL("/tmp/repo.git")
+	if _, err := NewReleaseProvider(ForgeGitHub, local, "", ""); err == nil {
+		t.Error("local repository accepted")
+	}
+	bitbucket, _ := ParseRepoURL("https://bitbucket.org/owner/repo.git")
+	if _, err := NewReleaseProvider(ForgeBitbucket, bitbucket, "", ""); err == nil {
+		t.Error("bitbucket accepted")
+	}
+}
+
+// writeAsset 在临时目录中创建附件
+func writeAsset(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGitHubUploadAsset(t *testing.T) {
+	fake := newFakeForge(t, map[string]fakeResponse{
+		"POST /uploads/repos/owner/repo/releases/42/assets": {Status: http.StatusCreated, Body: `{"id":1}`},
+	})
+	provider := newTestProvider(t, ForgeGitHub, "https://github.com/owner/repo.git", fake.URL+"/api/v3")
+	asset := writeAsset(t, "app linux.tar.gz", "binary data")
+
+	release := &ReleaseInfo{ID: "42", TagName: "v1.2.0", UploadURL: fake.URL + "/uploads/repos/owner/repo/releases/42/assets{?name,label}"}
+	if err := provider.UploadAsset(context.Background(), release, asset); err != nil {
+		t.Fatalf("UploadAsset: %v", err)
+	}
+
+	requests := fake.recorded()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if req.Method != http.MethodPost || req.Path != "/uploads/repos/owner/repo/releases/42/assets" || req.Query != "name=app+linux.tar.gz" {
+		t.Errorf("request = %s %s?%s", req.Method, req.Path, req.Query)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/octet-stream" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if string(req.Body) != "binary data" {
+		t.Errorf("body = %q", req.Body)
+	}
+}
+
+func TestGitLabUploadAsset(t *testing.T) {
+	const packagePath = "/api/v4/projects/group%2Frepo/packages/generic/repo/v1.2.0/app.tar.gz"
+	const linksPath = "/api/v4/projects/group%2Frepo/releases/v1.2.0/assets/links"
+	fake := newFakeForge(t, map[string]fakeResponse{
+		"PUT " + packagePath: {Status: http.StatusCreated, Body: `{"message":"201 Created"}`},
+		"POST " + linksPath:  {Status: http.StatusCreated, Body: `{"id":1}`},
+	})
+	provider := newTestProvider(t, ForgeGitLab, "https://gitlab.example.com/group/repo.git", fake.URL+"/api/v4")
+	asset := writeAsset(t, "app.tar.gz", "binary data")
+
+	if err := provider.UploadAsset(context.Background(), &ReleaseInfo{ID: "v1.2.0", TagName: "v1.2.0"}, asset); err != nil {
+		t.Fatalf("UploadAsset: %v", err)
+	}
+
+	requests := fake.recorded()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+
+	// 先上传到通用软件包仓库
+	upload := requests[0]
+	if upload.Method != http.MethodPut || upload.Path != packagePath {
+		t.Errorf("upload = %s %s, want PUT %s", upload.Method, upload.Path, packagePath)
+	}
+	if got := upload.Header.Get("Content-Type"); got != "application/octet-stream" {
+		t.Errorf("upload Content-Type = %q", got)
+	}
+	if got := upload.Header.Get("Private-Token"); got != "secret" {
+		t.Errorf("upload PRIVATE-TOKEN = %q", got)
+	}
+	if string(upload.Body) != "binary data" {
+		t.Errorf("upload body = %q", upload.Body)
+	}
+
+	// 再把软件包地址作为链接添加到发布
+	link := requests[1]
+	if link.Method != http.MethodPost || link.Path != linksPath {
+		t.Errorf("link = %s %s, want POST %s", link.Method, link.Path, linksPath)
+	}
+	if got := link.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("link Content-Type = %q", got)
+	}
+	want := map[string]interface{}{
+		"name":      "app.tar.gz",
+		"url":       fake.URL + packagePath,
+		"link_type": "package",
+	}
+	if body := decodeJSONBody(t, link); !reflect.DeepEqual(body, want) {
+		t.Errorf("link body = %v, want %v", body, want)
+	}
+}
+
+func TestGiteaUploadAsset(t *testing.T) {
+	fake := newFakeForge(t, map[string]fakeResponse{
+		"POST /api/v1/repos/owner/repo/releases/7/assets": {Status: http.StatusCreated, Body: `{"id":1}`},
+	})
+	provider := newTestProvider(t, ForgeGitea, "https://gitea.example.com/owner/repo.git", fake.URL+"/api/v1")
+	asset := writeAsset(t, "app.tar.gz", "binary data")
+
+	if err := provider.UploadAsset(context.Background(), &ReleaseInfo{ID: "7", TagName: "v1.2.0"}, asset); err != nil {
+		t.Fatalf("UploadAsset: %v", err)
+	}
+
+	requests := fake.recorded()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if req.Method != http.MethodPost || req.Path != "/api/v1/repos/owner/repo/releases/7/assets" || req.Query != "name=app.tar.gz" {
+		t.Errorf("request = %s %s?%s", req.Method, req.Path, req.Query)
+	}
+	if got := req.Header.Get("Authorization"); got != "token secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+
+	// 请求体是只包含 attachment 文件的 multipart 表单
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Content-Type = %q", req.Header.Get("Content-Type"))
+	}
+	reader := multipart.NewReader(bytes.NewReader(req.Body), params["boundary"])
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatalf("read multipart: %v", err)
+	}
+	content, _ := io.ReadAll(part)
+	if part.FormName() != "attachment" || part.FileName() != "app.tar.gz" || string(content) != "binary data" {
+		t.Errorf("part = %s %s %q", part.FormName(), part.FileName(), content)
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("unexpected extra part: %v", err)
+	}
+}
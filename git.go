@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 )
 
 // GitOperations 包含所有 Git 相关操作
@@ -17,11 +22,12 @@ type GitOperations struct {
 	repoPath string
 }
 
-// NewGitOperations 创建新的 Git 操作实例
+// NewGitOperations 创建新的 Git 操作实例，repoPath 可以是仓库中的子目录
 func NewGitOperations(repoPath string) (*GitOperations, error) {
-	repo, err := git.PlainOpen(repoPath)
+	debugf(msg("verbose.git_repo"), repoPath)
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open repository: %v", err)
+		return nil, fmt.Errorf(msg("git.open_failed"), err)
 	}
 
 	return &GitOperations{
@@ -32,10 +38,14 @@ func NewGitOperations(repoPath string) (*GitOperations, error) {
 
 // CreateTag 创建新的 Git 标签
 func (g *GitOperations) CreateTag(tagName, message string) error {
+	if dryRunSkip(msg("dryrun.create_tag", tagName)) {
+		return nil
+	}
+
 	// 获取当前 HEAD 引用
 	head, err := g.repo.Head()
 	if err != nil {
-		return fmt.Errorf("failed to get HEAD: %v", err)
+		return fmt.Errorf(msg("git.head_failed"), err)
 	}
 
 	// 创建标签对象
@@ -49,52 +59,70 @@ func (g *GitOperations) CreateTag(tagName, message string) error {
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to create tag: %v", err)
+		return fmt.Errorf(msg("git.create_tag_failed"), err)
 	}
 
-	fmt.Printf("Tag '%s' created successfully\n", tagName)
+	fmt.Println(msg("git.tag_created", tagName))
 	return nil
 }
 
-// PushTag 推送标签到远程仓库
-func (g *GitOperations) PushTag(tagName string) error {
+// PushTag 推送标签到指定的远程仓库
+func (g *GitOperations) PushTag(ctx context.Context, remoteName, tagName string) error {
+	if dryRunSkip(msg("dryrun.push_tag", tagName, remoteName)) {
+		return nil
+	}
+
 	// 获取远程仓库配置
-	remote, err := g.repo.Remote("origin")
+	remote, err := g.repo.Remote(remoteName)
 	if err != nil {
-		return fmt.Errorf("failed to get remote 'origin': %v", err)
+		return fmt.Errorf(msg("git.remote_failed"), remoteName, err)
 	}
 
 	// 推送标签
-	err = remote.Push(&git.PushOptions{
+	err = remote.PushContext(ctx, &git.PushOptions{
+		RemoteName: remoteName,
 		RefSpecs: []config.RefSpec{
 			config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tagName, tagName)),
 		},
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to push tag: %v", err)
+		return fmt.Errorf(msg("git.push_tag_failed"), err)
 	}
 
-	fmt.Printf("Tag '%s' pushed to remote successfully\n", tagName)
+	fmt.Println(msg("git.tag_pushed", tagName, remoteName))
 	return nil
 }
 
-// ListTags 获取所有标签列表
-func (g *GitOperations) ListTags() ([]string, error) {
+// DeleteTag 删除本地标签
+func (g *GitOperations) DeleteTag(tagName string) error {
+	if dryRunSkip(msg("dryrun.delete_tag", tagName)) {
+		return nil
+	}
+	if err := g.repo.DeleteTag(tagName); err != nil {
+		return fmt.Errorf(msg("git.delete_tag_failed"), tagName, err)
+	}
+	return nil
+}
+
+// ListTags 获取标签列表，prefix 不为空时只返回带有该前缀的标签，例如 monorepo 中某个包的标签
+func (g *GitOperations) ListTags(prefix string) ([]string, error) {
 	tagRefs, err := g.repo.Tags()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tags: %v", err)
+		return nil, fmt.Errorf(msg("git.tags_failed"), err)
 	}
 
 	var tags []string
 	err = tagRefs.ForEach(func(tagRef *plumbing.Reference) error {
 		tagName := tagRef.Name().Short()
-		tags = append(tags, tagName)
+		if strings.HasPrefix(tagName, prefix) {
+			tags = append(tags, tagName)
+		}
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to iterate tags: %v", err)
+		return nil, fmt.Errorf(msg("git.iterate_tags_failed"), err)
 	}
 
 	// 按版本号排序
@@ -104,16 +132,20 @@ func (g *GitOperations) ListTags() ([]string, error) {
 
 // CheckoutTag 切换到指定标签
 func (g *GitOperations) CheckoutTag(tagName string) error {
+	if dryRunSkip(msg("dryrun.checkout", tagName)) {
+		return nil
+	}
+
 	// 获取工作树
 	worktree, err := g.repo.Worktree()
 	if err != nil {
-		return fmt.Errorf("failed to get worktree: %v", err)
+		return fmt.Errorf(msg("git.worktree_failed"), err)
 	}
 
 	// 获取标签引用
 	tagRef, err := g.repo.Tag(tagName)
 	if err != nil {
-		return fmt.Errorf("failed to get tag '%s': %v", tagName, err)
+		return fmt.Errorf(msg("git.tag_failed"), tagName, err)
 	}
 
 	// 切换到标签
@@ -122,10 +154,10 @@ func (g *GitOperations) CheckoutTag(tagName string) error {
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to checkout tag '%s': %v", tagName, err)
+		return fmt.Errorf(msg("git.checkout_failed"), tagName, err)
 	}
 
-	fmt.Printf("Switched to tag '%s'\n", tagName)
+	fmt.Println(msg("git.switched", tagName))
 	return nil
 }
 
@@ -133,7 +165,7 @@ func (g *GitOperations) CheckoutTag(tagName string) error {
 func (g *GitOperations) GetCurrentBranch() (string, error) {
 	head, err := g.repo.Head()
 	if err != nil {
-		return "", fmt.Errorf("failed to get HEAD: %v", err)
+		return "", fmt.Errorf(msg("git.head_failed"), err)
 	}
 
 	if head.Name().IsBranch() {
@@ -144,11 +176,11 @@ func (g *GitOperations) GetCurrentBranch() (string, error) {
 	return head.Hash().String()[:8], nil
 }
 
-// GetRemoteURL 获取远程仓库 URL
-func (g *GitOperations) GetRemoteURL() (string, error) {
-	remote, err := g.repo.Remote("origin")
+// GetRemoteURL 获取指定远程仓库的 URL
+func (g *GitOperations) GetRemoteURL(remoteName string) (string, error) {
+	remote, err := g.repo.Remote(remoteName)
 	if err != nil {
-		return "", fmt.Errorf("failed to get remote 'origin': %v", err)
+		return "", fmt.Errorf(msg("git.remote_failed"), remoteName, err)
 	}
 
 	config := remote.Config()
@@ -156,49 +188,135 @@ func (g *GitOperations) GetRemoteURL() (string, error) {
 		return config.URLs[0], nil
 	}
 
-	return "", fmt.Errorf("no remote URL found")
+	return "", errors.New(msg("git.no_remote_url"))
+}
+
+// AddRemote 添加远程仓库
+func (g *GitOperations) AddRemote(remoteName, url string) error {
+	if dryRunSkip(msg("dryrun.add_remote", remoteName, url)) {
+		return nil
+	}
+	_, err := g.repo.CreateRemote(&config.RemoteConfig{
+		Name: remoteName,
+		URLs: []string{url},
+	})
+	if err != nil {
+		return fmt.Errorf(msg("git.add_remote_failed"), remoteName, err)
+	}
+	return nil
+}
+
+// SetRemoteURL 修改远程仓库地址，保留原有的 fetch 配置
+func (g *GitOperations) SetRemoteURL(remoteName, url string) error {
+	if dryRunSkip(msg("dryrun.set_remote", remoteName, url)) {
+		return nil
+	}
+
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return fmt.Errorf(msg("git.read_config_failed"), err)
+	}
+
+	remote, ok := cfg.Remotes[remoteName]
+	if !ok {
+		return errors.New(msg("git.remote_not_found", remoteName))
+	}
+	remote.URLs = []string{url}
+
+	if err := g.repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf(msg("git.update_remote_failed"), remoteName, err)
+	}
+	return nil
+}
+
+// ListRemotes 获取所有远程仓库名称
+func (g *GitOperations) ListRemotes() ([]string, error) {
+	remotes, err := g.repo.Remotes()
+	if err != nil {
+		return nil, fmt.Errorf(msg("git.list_remotes_failed"), err)
+	}
+
+	names := make([]string, 0, len(remotes))
+	for _, remote := range remotes {
+		names = append(names, remote.Config().Name)
+	}
+	sort.Strings(names)
+	return names, nil
 }
 
-// IsGitRepository 检查指定路径是否为 Git 仓库
+// AheadBehind 计算本地分支相对远程跟踪分支领先和落后的提交数
+// 基于最近一次 fetch 得到的 refs/remotes/<remote>/<branch>
+func (g *GitOperations) AheadBehind(remoteName, branch string) (ahead, behind int, err error) {
+	return g.aheadBehind(branch, remoteName, branch)
+}
+
+// aheadBehind 计算本地分支相对远程仓库中指定分支领先和落后的提交数
+func (g *GitOperations) aheadBehind(branch, remoteName, remoteBranch string) (ahead, behind int, err error) {
+	local, err := g.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return 0, 0, fmt.Errorf(msg("git.branch_failed"), branch, err)
+	}
+
+	remote, err := g.repo.Reference(plumbing.NewRemoteReferenceName(remoteName, remoteBranch), true)
+	if err != nil {
+		return 0, 0, fmt.Errorf(msg("git.remote_branch_missing"), remoteName, remoteBranch, err)
+	}
+
+	return g.countDivergence(local.Hash(), remote.Hash())
+}
+
+// countDivergence 计算 a 有而 b 没有、b 有而 a 没有的提交数，只读取两者分叉之后的历史
+func (g *GitOperations) countDivergence(a, b plumbing.Hash) (onlyA, onlyB int, err error) {
+	commitsA, commitsB, err := g.divergence(a, b)
+	return len(commitsA), len(commitsB), err
+}
+
+// IsGitRepository 检查指定路径是否在 Git 仓库中，会向上查找 .git
 func IsGitRepository(path string) bool {
-	_, err := git.PlainOpen(path)
+	_, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
 	return err == nil
 }
 
 // InitRepository 初始化 Git 仓库
 func InitRepository(path string) error {
+	if dryRunSkip(msg("dryrun.init_repo", path)) {
+		return nil
+	}
 	_, err := git.PlainInit(path, false)
 	if err != nil {
-		return fmt.Errorf("failed to initialize repository: %v", err)
+		return fmt.Errorf(msg("git.init_failed"), err)
 	}
 
-	fmt.Printf("Initialized empty Git repository in %s\n", path)
+	fmt.Println(msg("git.initialized", path))
 	return nil
 }
 
 // CloneRepository 克隆远程仓库
 func CloneRepository(url, path string) error {
+	if dryRunSkip(msg("dryrun.clone", url, path)) {
+		return nil
+	}
 	_, err := git.PlainClone(path, false, &git.CloneOptions{
 		URL: url,
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to clone repository: %v", err)
+		return fmt.Errorf(msg("git.clone_failed"), err)
 	}
 
-	fmt.Printf("Repository cloned successfully to %s\n", path)
+	fmt.Println(msg("git.cloned", path))
 	return nil
 }
 
 // GetLatestTag 获取最新的标签
-func (g *GitOperations) GetLatestTag() (string, error) {
-	tags, err := g.ListTags()
+func (g *GitOperations) GetLatestTag(prefix string) (string, error) {
+	tags, err := g.ListTags(prefix)
 	if err != nil {
 		return "", err
 	}
 
 	if len(tags) == 0 {
-		return "", fmt.Errorf("no tags found")
+		return "", errors.New(msg("git.no_tags"))
 	}
 
 	// 返回最后一个标签（按字母顺序排序后的最后一个）
@@ -209,6 +327,287 @@ func (g *GitOperations) GetLatestTag() (string, error) {
 func (g *GitOperations) ValidateRepository() error {
 	// 暂时跳过严格的状态检查，因为 go-git 库可能有误报
 	// 在实际使用中，用户应该确保工作树是干净的
-	fmt.Println("Warning: Skipping strict repository validation")
+	fmt.Println(msg("git.skip_validation"))
 	return nil
 }
+
+// GetHeadCommit 获取 HEAD 指向的提交哈希
+func (g *GitOperations) GetHeadCommit() (string, error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf(msg("git.head_failed"), err)
+	}
+	return head.Hash().String(), nil
+}
+
+// ResolveTag 获取标签指向的提交哈希，附注标签会解析到其指向的提交
+func (g *GitOperations) ResolveTag(tagName string) (string, error) {
+	ref, err := g.repo.Tag(tagName)
+	if err != nil {
+		return "", fmt.Errorf(msg("git.tag_failed"), tagName, err)
+	}
+
+	tag, err := g.repo.TagObject(ref.Hash())
+	switch err {
+	case nil:
+		commit, err := tag.Commit()
+		if err != nil {
+			return "", fmt.Errorf(msg("git.resolve_tag_failed"), tagName, err)
+		}
+		return commit.Hash.String(), nil
+	case plumbing.ErrObjectNotFound:
+		// 轻量标签直接指向提交
+		return ref.Hash().String(), nil
+	default:
+		return "", fmt.Errorf(msg("git.resolve_tag_failed"), tagName, err)
+	}
+}
+
+// GetUserIdentity 获取 git 配置中的用户名和邮箱，未配置时返回空字符串
+func (g *GitOperations) GetUserIdentity() string {
+	cfg, err := g.repo.ConfigScoped(config.GlobalScope)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case cfg.User.Name != "" && cfg.User.Email != "":
+		return fmt.Sprintf("%s <%s>", cfg.User.Name, cfg.User.Email)
+	case cfg.User.Name != "":
+		return cfg.User.Name
+	default:
+		return cfg.User.Email
+	}
+}
+
+// HeadState 获取 HEAD 的位置：在分支上时返回分支名，分离状态时返回指向 HEAD 的标签，
+// 没有标签时返回提交哈希的前 8 位
+func (g *GitOperations) HeadState() (name string, detached bool, err error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return "", false, fmt.Errorf(msg("git.head_failed"), err)
+	}
+	if head.Name().IsBranch() {
+		return head.Name().Short(), false, nil
+	}
+
+	tags, err := g.tagCommits()
+	if err != nil {
+		return "", true, err
+	}
+	if names := tags[head.Hash()]; len(names) > 0 {
+		return names[len(names)-1], true, nil
+	}
+	return head.Hash().String()[:8], true, nil
+}
+
+// ChangedFiles 获取工作区中有修改（包括未跟踪文件）的文件数
+func (g *GitOperations) ChangedFiles() (int, error) {
+	worktree, err := g.repo.Worktree()
+	if err != nil {
+		return 0, fmt.Errorf(msg("git.worktree_failed"), err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return 0, fmt.Errorf(msg("git.status_failed"), err)
+	}
+
+	changed := 0
+	for _, file := range status {
+		if file.Staging != git.Unmodified || file.Worktree != git.Unmodified {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// Upstream 获取分支配置的上游远程仓库和分支，未配置时 ok 为 false
+func (g *GitOperations) Upstream(branch string) (remoteName, remoteBranch string, ok bool) {
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return "", "", false
+	}
+	b, found := cfg.Branches[branch]
+	if !found || b.Remote == "" || b.Merge == "" {
+		return "", "", false
+	}
+	return b.Remote, b.Merge.Short(), true
+}
+
+// AheadBehindUpstream 计算本地分支相对上游分支领先和落后的提交数
+func (g *GitOperations) AheadBehindUpstream(branch, remoteName, remoteBranch string) (ahead, behind int, err error) {
+	return g.aheadBehind(branch, remoteName, remoteBranch)
+}
+
+// DescribeHead 获取从 HEAD 可达的最近标签以及此后的提交数，prefix 不为空时只考虑带有该前缀的标签，
+// dir 不为空时只统计修改了该目录的提交
+func (g *GitOperations) DescribeHead(prefix, dir string) (tag string, since int, err error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return "", 0, fmt.Errorf(msg("git.head_failed"), err)
+	}
+	tags, err := g.tagCommits()
+	if err != nil {
+		return "", 0, err
+	}
+
+	iter, err := g.repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return "", 0, fmt.Errorf(msg("git.history_failed"), err)
+	}
+	defer iter.Close()
+
+	var tagged plumbing.Hash
+	err = iter.ForEach(func(commit *object.Commit) error {
+		for i := len(tags[commit.Hash]) - 1; i >= 0; i-- {
+			if name := tags[commit.Hash][i]; strings.HasPrefix(name, prefix) {
+				tag, tagged = name, commit.Hash
+				return storer.ErrStop
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf(msg("git.history_failed"), err)
+	}
+	if tag == "" {
+		return "", 0, errors.New(msg("git.no_tags"))
+	}
+
+	if dir == "" {
+		since, _, err = g.countDivergence(head.Hash(), tagged)
+		return tag, since, err
+	}
+	commits, err := g.commitsBetween(head.Hash(), tagged, dir)
+	return tag, len(commits), err
+}
+
+// CommitInfo 提交的摘要
+type CommitInfo struct {
+	Hash    string `json:"hash" yaml:"hash"`
+	Subject string `json:"subject" yaml:"subject"`
+	Author  string `json:"author" yaml:"author"`
+}
+
+// CommitsSince 返回从 HEAD 可达、从标签 since 不可达的提交，按时间从新到旧排列
+// since 为空时返回所有提交，dir 不为空时只返回修改了该目录的提交
+func (g *GitOperations) CommitsSince(since, dir string) ([]CommitInfo, error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf(msg("git.head_failed"), err)
+	}
+	var stop plumbing.Hash
+	if since != "" {
+		commit, err := g.ResolveTag(since)
+		if err != nil {
+			return nil, err
+		}
+		stop = plumbing.NewHash(commit)
+	}
+
+	commits, err := g.commitsBetween(head.Hash(), stop, dir)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]CommitInfo, 0, len(commits))
+	for _, commit := range commits {
+		subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		infos = append(infos, CommitInfo{Hash: commit.Hash.String(), Subject: subject, Author: commit.Author.Name})
+	}
+	return infos, nil
+}
+
+// commitsBetween 返回从 from 可达、从 stop 不可达且修改了 dir 的提交，按提交时间从新到旧排列，stop 为零值时不排除提交
+func (g *GitOperations) commitsBetween(from, stop plumbing.Hash, dir string) ([]*object.Commit, error) {
+	commits, _, err := g.divergence(from, stop)
+	if err != nil || dir == "" {
+		return commits, err
+	}
+	prefix, err := g.repoPathOf(dir)
+	if err != nil || prefix == "" {
+		return commits, err
+	}
+
+	filtered := commits[:0]
+	for _, commit := range commits {
+		touched, err := touchesPath(commit, prefix)
+		if err != nil {
+			return nil, fmt.Errorf(msg("git.history_failed"), err)
+		}
+		if touched {
+			filtered = append(filtered, commit)
+		}
+	}
+	return filtered, nil
+}
+
+// repoPathOf 把目录转换为相对于仓库根目录的斜杠路径，仓库根目录本身返回空字符串
+func (g *GitOperations) repoPathOf(dir string) (string, error) {
+	worktree, err := g.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf(msg("git.worktree_failed"), err)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	root, abs := worktree.Filesystem.Root(), filepath.Clean(abs)
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", errors.New(msg("git.outside_repo", dir))
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// TagExists 检查标签是否存在
+func (g *GitOperations) TagExists(tagName string) bool {
+	_, err := g.repo.Tag(tagName)
+	return err == nil
+}
+
+// tagCommits 返回每个提交上的标签，附注标签解析到其指向的提交
+func (g *GitOperations) tagCommits() (map[plumbing.Hash][]string, error) {
+	tagRefs, err := g.repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf(msg("git.tags_failed"), err)
+	}
+
+	commits := make(map[plumbing.Hash][]string)
+	err = tagRefs.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if tag, err := g.repo.TagObject(hash); err == nil {
+			commit, err := tag.Commit()
+			if err != nil {
+				return nil // 指向非提交对象的标签
+			}
+			hash = commit.Hash
+		}
+		commits[hash] = append(commits[hash], ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(msg("git.iterate_tags_failed"), err)
+	}
+	for _, names := range commits {
+		sort.Strings(names)
+	}
+	return commits, nil
+}
+
+// CountCommits 统计从 HEAD 可达的提交数
+func (g *GitOperations) CountCommits() (int, error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return 0, fmt.Errorf(msg("git.head_failed"), err)
+	}
+	count, _, err := g.countDivergence(head.Hash(), plumbing.ZeroHash)
+	return count, err
+}
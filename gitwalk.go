@@ -0,0 +1,149 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// 遍历两个提交的历史时，记录提交可以从哪一边到达
+const (
+	reachableFromA uint8 = 1 << iota
+	reachableFromB
+	reachableFromBoth = reachableFromA | reachableFromB
+)
+
+// commitQueue 按提交时间从新到旧出队的优先队列
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int           { return len(q) }
+func (q commitQueue) Less(i, j int) bool { return q[i].Committer.When.After(q[j].Committer.When) }
+func (q commitQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)        { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// divergence 返回 a 可达而 b 不可达、b 可达而 a 不可达的提交，按提交时间从新到旧排列，b 为零值时返回 a 的全部历史。
+// 与 git rev-list a...b 相同，从两端按提交时间同时向下遍历并标记来源，
+// 队列中只剩两边都能到达的提交时，它们以下的历史都是共同祖先，不再继续读取
+func (g *GitOperations) divergence(a, b plumbing.Hash) (onlyA, onlyB []*object.Commit, err error) {
+	flags := make(map[plumbing.Hash]uint8)
+	commits := make(map[plumbing.Hash]*object.Commit)
+	queue := &commitQueue{}
+
+	// mark 为提交加上来源标记，标记有变化时重新入队，把新标记传递给祖先
+	mark := func(hash plumbing.Hash, flag uint8) error {
+		if hash.IsZero() || flags[hash]|flag == flags[hash] {
+			return nil
+		}
+		flags[hash] |= flag
+		commit, ok := commits[hash]
+		if !ok {
+			if commit, err = g.repo.CommitObject(hash); err != nil {
+				return fmt.Errorf(msg("git.history_failed"), err)
+			}
+			commits[hash] = commit
+		}
+		heap.Push(queue, commit)
+		return nil
+	}
+	if err := mark(a, reachableFromA); err != nil {
+		return nil, nil, err
+	}
+	if err := mark(b, reachableFromB); err != nil {
+		return nil, nil, err
+	}
+
+	for queue.Len() > 0 && !allReachableFromBoth(*queue, flags) {
+		commit := heap.Pop(queue).(*object.Commit)
+		for _, parent := range commit.ParentHashes {
+			if err := mark(parent, flags[commit.Hash]); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for hash, flag := range flags {
+		switch flag {
+		case reachableFromA:
+			onlyA = append(onlyA, commits[hash])
+		case reachableFromB:
+			onlyB = append(onlyB, commits[hash])
+		}
+	}
+	sortCommitsByTime(onlyA)
+	sortCommitsByTime(onlyB)
+	return onlyA, onlyB, nil
+}
+
+// allReachableFromBoth 判断队列中的提交是否都能从两边到达
+func allReachableFromBoth(queue commitQueue, flags map[plumbing.Hash]uint8) bool {
+	for _, commit := range queue {
+		if flags[commit.Hash] != reachableFromBoth {
+			return false
+		}
+	}
+	return true
+}
+
+// sortCommitsByTime 按提交时间从新到旧排列，时间相同时按哈希排列，保证结果稳定
+func sortCommitsByTime(commits []*object.Commit) {
+	sort.Slice(commits, func(i, j int) bool {
+		if ti, tj := commits[i].Committer.When, commits[j].Committer.When; !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return commits[i].Hash.String() < commits[j].Hash.String()
+	})
+}
+
+// touchesPath 判断提交是否修改了 prefix 目录中的文件。与 git log -- <path> 相同，
+// 合并提交只有与每个父提交相比都有修改时才算，根提交与空树比较
+func touchesPath(commit *object.Commit, prefix string) (bool, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, err
+	}
+	parents := []*object.Tree{nil}
+	if commit.NumParents() > 0 {
+		parents = parents[:0]
+		err := commit.Parents().ForEach(func(parent *object.Commit) error {
+			parentTree, err := parent.Tree()
+			parents = append(parents, parentTree)
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, parentTree := range parents {
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return false, err
+		}
+		changed := false
+		for _, change := range changes {
+			if inRepoPath(change.From.Name, prefix) || inRepoPath(change.To.Name, prefix) {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// inRepoPath 判断仓库中的路径是否位于 prefix 目录中
+func inRepoPath(name, prefix string) bool {
+	return name != "" && (name == prefix || strings.HasPrefix(name, prefix+"/"))
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// testHistory 在临时目录中创建仓库，按顺序提交，提交时间每次递增一分钟
+type testHistory struct {
+	t        *testing.T
+	dir      string
+	repo     *git.Repository
+	worktree *git.Worktree
+	when     time.Time
+}
+
+func newTestHistory(t *testing.T) *testHistory {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testHistory{t: t, dir: dir, repo: repo, worktree: worktree, when: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// commit 写入文件并提交，parents 不为空时创建合并提交
+func (h *testHistory) commit(file string, parents ...plumbing.Hash) plumbing.Hash {
+	h.t.Helper()
+	path := filepath.Join(h.dir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		h.t.Fatal(err)
+	}
+	h.when = h.when.Add(time.Minute)
+	if err := os.WriteFile(path, []byte(h.when.String()), 0644); err != nil {
+		h.t.Fatal(err)
+	}
+	if _, err := h.worktree.Add(file); err != nil {
+		h.t.Fatal(err)
+	}
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: h.when}
+	hash, err := h.worktree.Commit("update "+file, &git.CommitOptions{Author: signature, Committer: signature, Parents: parents})
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return hash
+}
+
+// checkout 把 HEAD 移到指定提交，之后的提交以它为父提交
+func (h *testHistory) checkout(hash plumbing.Hash) {
+	h.t.Helper()
+	if err := h.worktree.Checkout(&git.CheckoutOptions{Hash: hash, Force: true}); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+func (h *testHistory) ops() *GitOperations {
+	return &GitOperations{repo: h.repo, repoPath: h.dir}
+}
+
+func TestCountDivergence(t *testing.T) {
+	h := newTestHistory(t)
+	base := h.commit("a.txt")
+	for i := 0; i < 20; i++ {
+		base = h.commit("a.txt")
+	}
+	local := h.commit("local.txt")
+	local = h.commit("local.txt")
+	local = h.commit("local.txt")
+	h.checkout(base)
+	remote := h.commit("remote.txt")
+	remote = h.commit("remote.txt")
+
+	tests := []struct {
+		name          string
+		a, b          plumbing.Hash
+		ahead, behind int
+	}{
+		{"diverged", local, remote, 3, 2},
+		{"reversed", remote, local, 2, 3},
+		{"same commit", local, local, 0, 0},
+		{"ancestor", base, local, 0, 3},
+		{"whole history", local, plumbing.ZeroHash, 24, 0},
+	}
+	for _, tc := range tests {
+		ahead, behind, err := h.ops().countDivergence(tc.a, tc.b)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if ahead != tc.ahead || behind != tc.behind {
+			t.Errorf("%s: countDivergence = %d, %d; want %d, %d", tc.name, ahead, behind, tc.ahead, tc.behind)
+		}
+	}
+}
+
+func TestCountDivergenceAfterMerge(t *testing.T) {
+	h := newTestHistory(t)
+	base := h.commit("a.txt")
+	feature := h.commit("feature.txt")
+	h.checkout(base)
+	trunk := h.commit("main.txt")
+	h.commit("main.txt", trunk, feature)
+	after := h.commit("main.txt")
+
+	// feature 已经合并，领先的提交只有 trunk、合并提交和 after
+	ahead, behind, err := h.ops().countDivergence(after, feature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ahead != 3 || behind != 0 {
+		t.Errorf("countDivergence = %d, %d; want 3, 0", ahead, behind)
+	}
+}
+
+func TestCommitsBetweenFiltersDirectory(t *testing.T) {
+	h := newTestHistory(t)
+	h.commit("api/main.go")
+	stop := h.commit("web/index.html")
+	first := h.commit("api/main.go")
+	h.commit("web/index.html")
+	second := h.commit("api/handler.go")
+
+	t.Chdir(h.dir)
+	commits, err := h.ops().commitsBetween(second, stop, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []plumbing.Hash
+	for _, commit := range commits {
+		got = append(got, commit.Hash)
+	}
+	if len(got) != 2 || got[0] != second || got[1] != first {
+		t.Errorf("commitsBetween = %v, want [%s %s]", got, second, first)
+	}
+}
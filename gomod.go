@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GoConfig Go 模块检查配置
+type GoConfig struct {
+	SkipModuleCheck bool `yaml:"skip_module_check,omitempty"` // 不检查标签与 go.mod 中的模块路径是否一致
+	Verify          bool `yaml:"verify,omitempty"`            // 构建后执行 go list -m 和 go mod verify
+}
+
+// 模块路径的主版本后缀，例如 example.com/m/v2 或 gopkg.in/yaml.v3
+var (
+	goMajorSuffixPattern = regexp.MustCompile(`/v([0-9]+)$`)
+	goPkgInSuffixPattern = regexp.MustCompile(`\.v([0-9]+)(?:-unstable)?$`)
+)
+
+// goModulePath 读取目录中 go.mod 声明的模块路径，没有 go.mod 时返回空字符串
+func goModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf(msg("gomod.read_failed"), err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			if modulePath, err := strconv.Unquote(fields[1]); err == nil {
+				return modulePath, nil
+			}
+			return fields[1], nil
+		}
+	}
+	return "", errors.New(msg("gomod.no_module"))
+}
+
+// goModuleMajor 返回模块路径后缀表示的主版本，没有后缀时返回 0
+func goModuleMajor(modulePath string) int {
+	pattern := goMajorSuffixPattern
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		pattern = goPkgInSuffixPattern
+	}
+	m := pattern.FindStringSubmatch(modulePath)
+	if m == nil {
+		return 0
+	}
+	major, _ := strconv.Atoi(m[1])
+	return major
+}
+
+// checkGoModuleMajor 检查版本与模块路径的主版本后缀是否一致：
+// v2 及以上的版本要求模块路径以 /vN 结尾，v0 和 v1 的模块路径不能带有后缀
+func checkGoModuleMajor(modulePath string, version semVersion) error {
+	suffix := goModuleMajor(modulePath)
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		if suffix != version.Major {
+			return fmt.Errorf(msg("gomod.major_mismatch"), version, modulePath, fmt.Sprintf(".v%d", version.Major))
+		}
+		return nil
+	}
+	switch {
+	case version.Major >= 2 && suffix != version.Major:
+		return fmt.Errorf(msg("gomod.major_mismatch"), version, modulePath, fmt.Sprintf("/v%d", version.Major))
+	case version.Major < 2 && suffix >= 2:
+		return fmt.Errorf(msg("gomod.major_unexpected"), version, modulePath, suffix)
+	}
+	return nil
+}
+
+// goModuleTagDir 返回模块目录相对于仓库根目录的路径，根目录返回空字符串。
+// 项目还不是 Git 仓库时发布会在项目根目录初始化仓库，因此按相对于项目根目录计算
+func goModuleTagDir(dir string) (string, error) {
+	if IsGitRepository(projectDir) {
+		gitOps, err := NewGitOperations(projectDir)
+		if err != nil {
+			return "", err
+		}
+		return gitOps.repoPathOf(dir)
+	}
+	dir = strings.Trim(path.Clean(filepath.ToSlash(dir)), "/")
+	if dir == "." {
+		return "", nil
+	}
+	return dir, nil
+}
+
+// checkGoModuleTag 目录中有 go.mod 时检查标签是否符合 Go 模块的规则：
+// 子目录中的模块使用 <目录>/vX.Y.Z 形式的标签，主版本与模块路径的 /vN 后缀一致
+func checkGoModuleTag(dir, tagName string) error {
+	modulePath, err := goModulePath(dir)
+	if err != nil || modulePath == "" {
+		return err
+	}
+	tagDir, err := goModuleTagDir(dir)
+	if err != nil {
+		return err
+	}
+
+	// go 命令只识别 <目录>/vX.Y.Z 形式的标签
+	prefix := packageTagPrefix(tagDir)
+	version, err := parseSemVersion(strings.TrimPrefix(tagName, strings.TrimSuffix(prefix, "v")))
+	if !strings.HasPrefix(tagName, prefix) || err != nil {
+		return fmt.Errorf(msg("gomod.tag_mismatch"), modulePath, tagName, prefix)
+	}
+	debugf(msg("verbose.go_module"), modulePath, tagName)
+	return checkGoModuleMajor(modulePath, version)
+}
+
+// checkReleaseGoModule 按配置检查选中的包或整个项目发布时使用的标签，config 为 nil 时按项目根目录检查
+func checkReleaseGoModule(config *Config, tagName string) error {
+	dir := ""
+	if config != nil {
+		if config.Go.SkipModuleCheck {
+			return nil
+		}
+		dir = packageDir(config)
+	}
+	return checkGoModuleTag(dir, tagName)
+}
+
+// verifyGoModule 在模块目录中执行 go list -m 和 go mod verify，确认模块可以被解析且依赖未被篡改
+func verifyGoModule(ctx context.Context, dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return nil
+	}
+	fmt.Println(msg("gomod.verify"))
+	for _, command := range []string{"go list -m", "go mod verify"} {
+		if err := runCommandIn(ctx, dir, command); err != nil {
+			return fmt.Errorf(msg("gomod.verify_failed"), err)
+		}
+	}
+	return nil
+}
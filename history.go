@@ -0,0 +1,253 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// 发布结果
+const (
+	ReleaseSucceeded = "success" // 所有步骤都已完成
+	ReleasePartial   = "partial" // 标签已推送，但部分远程仓库或托管平台发布失败
+	ReleaseFailed    = "failed"  // 标签没有推送成功，已删除本地标签
+)
+
+// ReleaseRecord 发布历史中的一条记录，只追加不修改
+type ReleaseRecord struct {
+	Version    string           `yaml:"version" json:"version"`
+	Tag        string           `yaml:"tag" json:"tag"`
+	Package    string           `yaml:"package,omitempty" json:"package,omitempty"` // monorepo 中发布的包
+	Commit     string           `yaml:"commit" json:"commit"`
+	Branch     string           `yaml:"branch" json:"branch"`
+	ReleasedBy string           `yaml:"released_by" json:"released_by"`
+	ReleasedAt string           `yaml:"released_at" json:"released_at"`
+	Artifacts  []ArtifactRecord `yaml:"artifacts,omitempty" json:"artifacts,omitempty"`
+	Outcome    string           `yaml:"outcome" json:"outcome"`
+	Detail     string           `yaml:"detail,omitempty" json:"detail,omitempty"` // 未完全成功时的原因
+}
+
+// ArtifactRecord 发布附件及其校验和
+type ArtifactRecord struct {
+	Name   string `yaml:"name" json:"name"`
+	SHA256 string `yaml:"sha256" json:"sha256"`
+}
+
+// recordRelease 把本次发布追加到 .repo.lock 的发布历史，成功或部分成功时同时更新当前版本（发布单个包时除外）；
+// 记录失败只输出警告，不影响发布结果，无法生成记录时返回 nil
+func recordRelease(version, outcome, detail string) *ReleaseRecord {
+	record, err := newReleaseRecord(version, outcome, detail)
+	if err != nil {
+		fmt.Println(msg("history.record_failed", err))
+		return nil
+	}
+
+	lock, err := LoadRepoLock()
+	if isRepoLockCorrupt(err) {
+		// 不覆盖已损坏的文件，避免丢失其中的发布历史
+		fmt.Println(msg("history.record_failed", err))
+		return record
+	}
+	if err != nil {
+		lock = &RepoLock{Branch: record.Branch}
+	}
+
+	lock.History = append(lock.History, *record)
+	// 当前版本是整个项目的版本，发布单个包时只记录历史
+	if outcome != ReleaseFailed && activePackage == "" {
+		lock.CurrentVersion = version
+		lock.LastUpdated = record.ReleasedAt
+	}
+	if err := SaveRepoLock(lock); err != nil {
+		fmt.Println(msg("history.record_failed", err))
+	}
+	return record
+}
+
+// newReleaseRecord 根据 git 状态和配置生成发布记录
+func newReleaseRecord(version, outcome, detail string) (*ReleaseRecord, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	gitOps, err := NewGitOperations(".")
+	if err != nil {
+		return nil, err
+	}
+
+	record := &ReleaseRecord{
+		Version:    version,
+		Tag:        releaseTagName(config, version),
+		Package:    activePackage,
+		ReleasedBy: gitOps.GetUserIdentity(),
+		ReleasedAt: time.Now().Format(time.RFC3339),
+		Outcome:    outcome,
+		Detail:     detail,
+	}
+	if record.ReleasedBy == "" {
+		if u, err := user.Current(); err == nil {
+			record.ReleasedBy = u.Username
+		}
+	}
+	if commit, err := gitOps.GetHeadCommit(); err == nil {
+		record.Commit = commit
+	}
+	if branch, err := gitOps.GetCurrentBranch(); err == nil {
+		record.Branch = branch
+	}
+	if record.Artifacts, err = artifactChecksums(config.Release.Assets); err != nil {
+		fmt.Println(msg("history.checksum_failed", err))
+	}
+	return record, nil
+}
+
+// artifactChecksums 计算发布附件的 SHA-256
+func artifactChecksums(patterns []string) ([]ArtifactRecord, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	assets, err := resolveReleaseAssets(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := make([]ArtifactRecord, 0, len(assets))
+	for _, asset := range assets {
+		sum, err := fileSHA256(asset)
+		if err != nil {
+			return artifacts, err
+		}
+		artifacts = append(artifacts, ArtifactRecord{Name: filepath.ToSlash(asset), SHA256: sum})
+	}
+	return artifacts, nil
+}
+
+// fileSHA256 计算文件的 SHA-256
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// handleHistory 处理 history 命令，--json 与 --output json 相同
+func handleHistory() error {
+	lock, err := LoadRepoLock()
+	if err != nil {
+		return configError("%w", err)
+	}
+
+	history := lock.History
+	if history == nil {
+		history = []ReleaseRecord{}
+	}
+	return emitResult(history, func() {
+		if len(history) == 0 {
+			fmt.Println(msg("history.none"))
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		// 表头使用英文，中文字符宽度不同会导致列无法对齐
+		fmt.Fprintln(w, "VERSION\tTAG\tCOMMIT\tBRANCH\tRELEASED BY\tRELEASED AT\tARTIFACTS\tOUTCOME")
+		for _, record := range history {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+				record.Version, record.Tag, shortHash(record.Commit), record.Branch,
+				record.ReleasedBy, record.ReleasedAt, len(record.Artifacts), record.Outcome)
+		}
+		w.Flush()
+	})
+}
+
+// historyVerifyResult ghc history verify 的结果
+type historyVerifyResult struct {
+	Checked  int              `json:"checked" yaml:"checked"`
+	Problems []historyProblem `json:"problems" yaml:"problems"`
+}
+
+// historyProblem 与 git 标签不一致的发布记录
+type historyProblem struct {
+	Version string `json:"version" yaml:"version"`
+	Tag     string `json:"tag" yaml:"tag"`
+	Commit  string `json:"commit" yaml:"commit"`                     // 发布时的提交
+	Actual  string `json:"actual,omitempty" yaml:"actual,omitempty"` // 标签现在指向的提交，标签不存在时为空
+}
+
+// handleHistoryVerify 检查发布历史中的每个标签是否仍然存在并指向记录的提交，
+// 失败的发布没有推送标签，不参与检查
+func handleHistoryVerify() error {
+	lock, err := LoadRepoLock()
+	if err != nil {
+		return configError("%w", err)
+	}
+	gitOps, err := NewGitOperations(".")
+	if err != nil {
+		return gitError("%w", err)
+	}
+
+	result := &historyVerifyResult{Problems: []historyProblem{}}
+	var checked []ReleaseRecord
+	problems := make(map[int]historyProblem)
+	for _, record := range lock.History {
+		if record.Outcome == ReleaseFailed {
+			continue
+		}
+		checked = append(checked, record)
+
+		commit, err := gitOps.ResolveTag(record.Tag)
+		if err != nil {
+			commit = ""
+		}
+		if err != nil || commit != record.Commit {
+			problem := historyProblem{Version: record.Version, Tag: record.Tag, Commit: record.Commit, Actual: commit}
+			problems[len(checked)-1] = problem
+			result.Problems = append(result.Problems, problem)
+		}
+	}
+	result.Checked = len(checked)
+
+	err = emitResult(result, func() {
+		for i, record := range checked {
+			problem, failed := problems[i]
+			switch {
+			case !failed:
+				fmt.Printf("  ✓ %s: %s\n", record.Version, record.Tag)
+			case problem.Actual == "":
+				fmt.Println(msg("history.tag_missing", record.Version, record.Tag))
+			default:
+				fmt.Println(msg("history.tag_moved", record.Version, record.Tag, shortHash(problem.Actual), shortHash(record.Commit)))
+			}
+		}
+		if len(result.Problems) == 0 {
+			fmt.Println(msg("history.consistent", result.Checked))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.Problems) > 0 {
+		return gitError(msg("history.mismatch"), len(result.Problems))
+	}
+	return nil
+}
+
+// shortHash 返回提交哈希的前 8 位
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
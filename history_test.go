@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestHistoryVerifyDetectsMovedAndDeletedTags(t *testing.T) {
+	h := newTestHistory(t)
+	first := h.commit("a.txt")
+	second := h.commit("b.txt")
+	t.Chdir(h.dir)
+	for tag, hash := range map[string]string{"v1.0.0": first.String(), "v1.1.0": second.String()} {
+		if _, err := h.repo.CreateTag(tag, plumbing.NewHash(hash), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// saveHistory 把发布记录写入 .repo.lock，失败的发布没有推送标签，不参与检查
+	saveHistory := func(records ...ReleaseRecord) {
+		t.Helper()
+		records = append(records, ReleaseRecord{Version: "0.9.0", Tag: "v0.9.0", Commit: first.String(), Outcome: ReleaseFailed})
+		if err := SaveRepoLock(&RepoLock{Branch: "main", History: records}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saveHistory(
+		ReleaseRecord{Version: "1.0.0", Tag: "v1.0.0", Commit: first.String(), Outcome: ReleaseSucceeded},
+		ReleaseRecord{Version: "1.1.0", Tag: "v1.1.0", Commit: second.String(), Outcome: ReleasePartial},
+	)
+	if err := handleHistoryVerify(); err != nil {
+		t.Fatalf("history verify with matching tags: %v", err)
+	}
+
+	// v1.1.0 发布时指向第一个提交，之后被移动到第二个提交
+	saveHistory(ReleaseRecord{Version: "1.1.0", Tag: "v1.1.0", Commit: first.String(), Outcome: ReleaseSucceeded})
+	if err := handleHistoryVerify(); !errors.Is(err, ErrGit) {
+		t.Errorf("history verify with a moved tag = %v, want a git error", err)
+	}
+
+	if err := h.repo.DeleteTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	saveHistory(ReleaseRecord{Version: "1.0.0", Tag: "v1.0.0", Commit: first.String(), Outcome: ReleaseSucceeded})
+	if err := handleHistoryVerify(); !errors.Is(err, ErrGit) {
+		t.Errorf("history verify with a deleted tag = %v, want a git error", err)
+	}
+}
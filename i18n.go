@@ -0,0 +1,724 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 支持的语言
+const (
+	LocaleZH = "zh-CN"
+	LocaleEN = "en"
+)
+
+// locale 当前的语言，main 启动时通过 setupLocale 选择
+var locale = LocaleZH
+
+// message 一条消息的各语言版本，两种语言写在同一项中，不会只缺少其中一种
+type message struct {
+	zh string
+	en string
+}
+
+// msg 返回 key 在当前语言下的消息，有 args 时按 fmt 格式化；
+// 不带 args 时返回格式本身，可以作为 fmt.Errorf 等函数的格式使用
+func msg(key string, args ...interface{}) string {
+	m, ok := messages[key]
+	if !ok {
+		return key
+	}
+	text := m.zh
+	if locale == LocaleEN && m.en != "" {
+		text = m.en
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// normalizeLocale 把 zh_CN.UTF-8、en_US 等写法转换为支持的语言，无法识别时返回空字符串
+func normalizeLocale(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	switch {
+	case value == "", value == "c", value == "posix":
+		return ""
+	case strings.HasPrefix(value, "zh"):
+		return LocaleZH
+	case strings.HasPrefix(value, "en"):
+		return LocaleEN
+	}
+	return ""
+}
+
+// setLocale 设置 --lang 指定的语言
+func setLocale(value string) error {
+	normalized := normalizeLocale(value)
+	if normalized == "" {
+		return fmt.Errorf(msg("cli.invalid_lang"), value, LocaleZH, LocaleEN)
+	}
+	locale = normalized
+	return nil
+}
+
+// langExplicit 是否通过 --lang 或 --set lang= 指定了语言
+var langExplicit bool
+
+// setupLocale 没有通过参数指定语言时，依次使用 GHC_LANG、配置文件中的 lang
+// 和 LC_ALL、LC_MESSAGES、LANG 环境变量，都没有时使用中文。
+// 此时还没有获取仓库锁，只读取配置文件中的 lang，不做迁移和校验
+func setupLocale() {
+	if langExplicit {
+		return
+	}
+
+	candidates := []string{os.Getenv(configEnvName("lang")), configuredLocale()}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		candidates = append(candidates, os.Getenv(name))
+	}
+	for _, candidate := range candidates {
+		if normalized := normalizeLocale(candidate); normalized != "" {
+			locale = normalized
+			return
+		}
+	}
+}
+
+// configuredLocale 读取本地配置、项目配置和用户配置中的 lang，优先级与配置分层相同
+func configuredLocale() string {
+	segments, _, err := parseConfigPath("lang")
+	if err != nil {
+		return ""
+	}
+	for _, path := range []string{LocalConfigFile, findProjectConfig(), globalConfigPath()} {
+		if path == "" || !fileExists(path) {
+			continue
+		}
+		doc, err := loadConfigDocument(path)
+		if err != nil {
+			continue
+		}
+		if node := lookupConfigNode(doc, segments); node != nil && node.Value != "" {
+			return node.Value
+		}
+	}
+	return ""
+}
+
+// messages 消息目录，键按命令分组
+var messages = map[string]message{
+	// 通用
+	"common.load_config_failed": {"加载配置失败: %w", "failed to load config: %w"},
+	"common.save_config_failed": {"保存配置失败: %w", "failed to save config: %w"},
+	"common.save_lock_failed":   {"保存仓库锁定文件失败: %w", "failed to save repo lock file: %w"},
+	"common.getwd_failed":       {"获取当前目录失败: %w", "failed to get current directory: %w"},
+	"common.not_git_repo":       {"当前目录不是 git 仓库", "not a git repository"},
+	"common.git_init_failed":    {"初始化 Git 操作失败: %w", "failed to initialize git operations: %w"},
+
+	// init
+	"init.already":              {"项目已经初始化，配置文件 %s 已存在", "project is already initialized, config file %s exists"},
+	"init.create_config_failed": {"创建配置文件失败: %w", "failed to create config file: %w"},
+	"init.create_lock_failed":   {"创建仓库锁定文件失败: %w", "failed to create repo lock file: %w"},
+	"init.success":              {"项目初始化成功！", "Project initialized!"},
+	"init.created_config":       {"已创建配置文件: %s", "Created config file: %s"},
+	"init.created_lock":         {"已创建锁定文件: %s", "Created lock file: %s"},
+	"init.bind_hint":            {"请使用 'ghc bind <repo-url>' 绑定仓库", "Use 'ghc bind <repo-url>' to bind a repository"},
+
+	// bind
+	"bind.missing_url":         {"请提供仓库地址", "please provide a repository URL"},
+	"bind.invalid_url":         {"请提供有效的仓库地址: %w", "please provide a valid repository URL: %w"},
+	"bind.incomplete_url":      {"请提供有效的仓库地址，应包含所有者和仓库名: %s", "please provide a valid repository URL with owner and name: %s"},
+	"bind.detect_forge_failed": {"识别托管平台失败: %w", "failed to detect forge: %w"},
+	"bind.success":             {"仓库绑定成功: %s", "Repository bound: %s"},
+	"bind.forge":               {"托管平台: %s", "Forge: %s"},
+	"bind.fix_failed":          {"修复远程仓库失败: %w", "failed to fix remotes: %w"},
+	"bind.fixed":               {"✓ 远程仓库与绑定的仓库一致", "✓ Remotes match the bound repository"},
+	"bind.mismatch":            {"⚠️ 远程仓库地址与绑定的仓库不一致:", "⚠️ Remote URLs differ from the bound repository:"},
+	"bind.fix_hint":            {"使用 'ghc bind --fix-remote' 把远程仓库改为配置中的地址", "Use 'ghc bind --fix-remote' to point remotes at the configured URL"},
+
+	// tag
+	"tag.empty_version":     {"版本号不能为空", "version cannot be empty"},
+	"tag.invalid_repo":      {"仓库状态无效: %w", "invalid repository state: %w"},
+	"tag.create_failed":     {"创建标签失败: %w", "failed to create tag: %w"},
+	"tag.push_failed":       {"推送标签失败: %w", "failed to push tag: %w"},
+	"tag.delete_failed":     {"⚠️ 删除本地标签 %s 失败: %v", "⚠️ Could not delete local tag %s: %v"},
+	"tag.deleted_unpushed":  {"已删除未推送的本地标签 %s", "Deleted unpushed local tag %s"},
+	"tag.load_lock_failed":  {"⚠️ 无法读取仓库锁定文件: %v", "⚠️ Could not load repo lock: %v"},
+	"tag.save_lock_failed":  {"⚠️ 无法保存仓库锁定文件: %v", "⚠️ Could not save repo lock: %v"},
+	"tag.partial":           {"⚠️ 标签只推送到了部分远程仓库", "⚠️ Tag was pushed to some remotes only"},
+	"tag.created":           {"✓ 标签 %s 已创建并推送", "✓ Tag %s created and pushed"},
+	"tag.list_failed":       {"获取标签列表失败: %w", "failed to list tags: %w"},
+	"tag.none":              {"暂无标签", "No tags found"},
+	"tag.available":         {"标签列表:", "Available tags:"},
+	"tag.latest":            {"最新标签: %s", "Latest tag: %s"},
+	"tag.checkout_failed":   {"切换标签失败: %w", "failed to check out tag: %w"},
+	"tag.checked_out":       {"✓ 已切换到标签 %s", "✓ Checked out tag %s"},
+	"tag.no_push_remotes":   {"没有可推送的远程仓库", "no remotes to push to"},
+	"tag.all_pushes_failed": {"所有远程仓库推送失败", "push failed on all remotes"},
+
+	// publish
+	"publish.profile":            {"使用发布方案: %s", "Using profile: %s"},
+	"publish.package":            {"发布包: %s（%s）", "Publishing package: %s (%s)"},
+	"publish.start":              {"开始发布项目，版本: %s", "Publishing version %s"},
+	"publish.log_failed":         {"⚠️ 无法记录命令日志: %v", "⚠️ Could not record command logs: %v"},
+	"publish.log_hint":           {"命令日志: %s（使用 'ghc logs %s' 查看）", "Command logs: %s (view with 'ghc logs %s')"},
+	"publish.step_build":         {"步骤 %d/%d: 编译项目...", "Step %d/%d: building the project..."},
+	"publish.build_failed":       {"编译失败: %w", "build failed: %w"},
+	"publish.build_ok":           {"✓ 编译成功", "✓ Build succeeded"},
+	"publish.step_repo":          {"步骤 %d/%d: 检查 Git 仓库...", "Step %d/%d: checking the git repository..."},
+	"publish.init_repo":          {"初始化 Git 仓库...", "Initializing the git repository..."},
+	"publish.init_repo_failed":   {"初始化 Git 仓库失败: %w", "failed to initialize the git repository: %w"},
+	"publish.repo_ok":            {"✓ Git 仓库就绪", "✓ Git repository ready"},
+	"publish.step_remotes":       {"步骤 %d/%d: 配置远程仓库...", "Step %d/%d: configuring remotes..."},
+	"publish.remotes_failed":     {"配置远程仓库失败: %w", "failed to configure remotes: %w"},
+	"publish.remotes_ok":         {"✓ 远程仓库配置完成", "✓ Remotes configured"},
+	"publish.step_commit":        {"步骤 %d/%d: 提交文件...", "Step %d/%d: committing files..."},
+	"publish.commit_failed":      {"提交文件失败: %w", "failed to commit files: %w"},
+	"publish.commit_ok":          {"✓ 文件提交完成", "✓ Files committed"},
+	"publish.step_push":          {"步骤 %d/%d: 推送到远程仓库...", "Step %d/%d: pushing to remotes..."},
+	"publish.push_failed":        {"推送失败: %w", "push failed: %w"},
+	"publish.push_ok":            {"✓ 推送完成", "✓ Push complete"},
+	"publish.step_tag":           {"步骤 %d/%d: 创建发布标签...", "Step %d/%d: creating the release tag..."},
+	"publish.tag_ok":             {"✓ 发布标签创建完成", "✓ Release tag created"},
+	"publish.canceled_before":    {"托管平台发布前已取消", "canceled before the forge release"},
+	"publish.release_incomplete": {"标签已推送但发布未完成: %w", "tag pushed but the release was not completed: %w"},
+	"publish.step_release":       {"步骤 %d/%d: 创建托管平台发布...", "Step %d/%d: creating the forge release..."},
+	"publish.release_failed":     {"创建发布失败: %v", "Failed to create the release: %v"},
+	"publish.release_partial":    {"⚠️ 标签已推送但发布未完成，版本: %s", "⚠️ Tag pushed but the release was not completed, version: %s"},
+	"publish.release_ok":         {"✓ 发布创建完成: %s", "✓ Release created: %s"},
+	"publish.remote_failures":    {"%d 个远程仓库操作失败", "%d remote operations failed"},
+	"publish.partial":            {"⚠️ 项目已部分发布，%d 个远程仓库操作失败，版本: %s", "⚠️ Partially published, %d remote operations failed, version: %s"},
+	"publish.success":            {"🎉 项目发布成功！版本: %s", "🎉 Published version %s"},
+	"publish.canceled":           {"发布已取消: %w", "publish canceled: %w"},
+	"publish.prebuild_failed":    {"预编译失败: %w", "pre-build failed: %w"},
+	"publish.no_repo":            {"未配置远程仓库地址，请先使用 'ghc bind <repo-url>' 绑定仓库", "no repository configured, run 'ghc bind <repo-url>' first"},
+	"publish.invalid_remotes":    {"远程仓库配置无效: %w", "invalid remotes config: %w"},
+	"publish.add_remote":         {"添加远程仓库 %s: %s", "Adding remote %s: %s"},
+	"publish.stage_failed":       {"添加文件失败: %w", "failed to stage files: %w"},
+
+	// 预编译和命令执行
+	"prebuild.start":   {"🔧 执行预编译钩子...", "🔧 Running pre-build hooks..."},
+	"prebuild.invalid": {"预编译配置无效: %w", "invalid pre-build config: %w"},
+	"prebuild.none":    {"✓ 没有需要执行的预编译步骤", "✓ No pre-build steps to run"},
+	"prebuild.done":    {"✓ 预编译钩子执行完成", "✓ Pre-build hooks finished"},
+	"command.empty":    {"空命令", "empty command"},
+	"command.timeout":  {"命令执行超时（%d秒）", "command timed out (%ds)"},
+	"command.run":      {"执行命令: %s", "Running: %s"},
+	"command.run_in":   {"执行命令（%s）: %s", "Running in %s: %s"},
+
+	// GitOperations
+	"git.open_failed":           {"打开仓库失败: %w", "failed to open repository: %w"},
+	"git.head_failed":           {"获取 HEAD 失败: %w", "failed to get HEAD: %w"},
+	"git.create_tag_failed":     {"写入标签失败: %w", "failed to write tag: %w"},
+	"git.tag_created":           {"标签 %s 创建成功", "Tag %s created"},
+	"git.remote_failed":         {"获取远程仓库 %s 失败: %w", "failed to get remote '%s': %w"},
+	"git.push_tag_failed":       {"推送标签失败: %w", "failed to push tag: %w"},
+	"git.tag_pushed":            {"标签 %s 已推送到远程仓库 %s", "Tag %s pushed to remote '%s'"},
+	"git.delete_tag_failed":     {"删除标签 %s 失败: %w", "failed to delete tag '%s': %w"},
+	"git.tags_failed":           {"获取标签失败: %w", "failed to get tags: %w"},
+	"git.iterate_tags_failed":   {"遍历标签失败: %w", "failed to iterate tags: %w"},
+	"git.worktree_failed":       {"获取工作区失败: %w", "failed to get worktree: %w"},
+	"git.status_failed":         {"获取工作区状态失败: %w", "failed to get status: %w"},
+	"git.tag_failed":            {"获取标签 %s 失败: %w", "failed to get tag '%s': %w"},
+	"git.resolve_tag_failed":    {"解析标签 %s 失败: %w", "failed to resolve tag '%s': %w"},
+	"git.checkout_failed":       {"切换到标签 %s 失败: %w", "failed to check out tag '%s': %w"},
+	"git.switched":              {"已切换到标签 %s", "Switched to tag %s"},
+	"git.no_remote_url":         {"没有找到远程仓库地址", "no remote URL found"},
+	"git.add_remote_failed":     {"添加远程仓库 %s 失败: %w", "failed to add remote '%s': %w"},
+	"git.read_config_failed":    {"读取 git 配置失败: %w", "failed to read git config: %w"},
+	"git.remote_not_found":      {"远程仓库 %s 不存在", "remote '%s' not found"},
+	"git.update_remote_failed":  {"更新远程仓库 %s 失败: %w", "failed to update remote '%s': %w"},
+	"git.list_remotes_failed":   {"获取远程仓库列表失败: %w", "failed to list remotes: %w"},
+	"git.branch_failed":         {"获取分支 %s 失败: %w", "failed to get branch '%s': %w"},
+	"git.remote_branch_missing": {"远程分支 %s/%s 不存在: %w", "remote branch '%s/%s' not found: %w"},
+	"git.history_failed":        {"读取提交历史失败: %w", "failed to read history: %w"},
+	"git.init_failed":           {"初始化仓库失败: %w", "failed to initialize repository: %w"},
+	"git.initialized":           {"已在 %s 初始化空的 Git 仓库", "Initialized empty Git repository in %s"},
+	"git.clone_failed":          {"克隆仓库失败: %w", "failed to clone repository: %w"},
+	"git.cloned":                {"仓库已克隆到 %s", "Repository cloned to %s"},
+	"git.outside_repo":          {"%s 不在 git 仓库中", "%s is outside the git repository"},
+	"git.no_tags":               {"没有标签", "no tags found"},
+	"git.skip_validation":       {"⚠️ 跳过严格的仓库校验", "⚠️ Skipping strict repository validation"},
+
+	// cli
+	"cli.unknown_command":   {"未知命令: %s", "unknown command: %s"},
+	"cli.wrong_args":        {"参数数量不正确", "wrong number of arguments"},
+	"cli.usage_line":        {"使用方法: %s", "Usage: %s"},
+	"cli.help_hint":         {"使用 '%s --help' 查看帮助", "Run '%s --help' for help"},
+	"cli.chdir_failed":      {"无法进入目录 %s: %w", "cannot change to directory %s: %w"},
+	"cli.dry_run":           {"[dry-run] %s", "[dry-run] %s"},
+	"verbose.command":       {"执行命令: %s %v", "running command: %s %v"},
+	"verbose.project_root":  {"项目根目录: %s", "project root: %s"},
+	"verbose.chdir":         {"工作目录: %s", "working directory: %s"},
+	"verbose.config_layer":  {"读取配置: %s", "loading config: %s"},
+	"verbose.git_repo":      {"打开 git 仓库: %s", "opening git repository: %s"},
+	"verbose.go_module":     {"Go 模块 %s，标签 %s", "Go module %s, tag %s"},
+	"verbose.lock_acquired": {"已获取仓库锁: %s", "acquired repository lock: %s"},
+	"dryrun.write":          {"写入 %s", "write %s"},
+	"dryrun.remove":         {"删除 %s", "remove %s"},
+	"dryrun.command":        {"执行 %s", "run %s"},
+	"dryrun.create_tag":     {"创建标签 %s", "create tag %s"},
+	"dryrun.push_tag":       {"推送标签 %s 到 %s", "push tag %s to %s"},
+	"dryrun.delete_tag":     {"删除标签 %s", "delete tag %s"},
+	"dryrun.checkout":       {"切换到标签 %s", "check out tag %s"},
+	"dryrun.add_remote":     {"添加远程仓库 %s: %s", "add remote %s: %s"},
+	"dryrun.set_remote":     {"修改远程仓库 %s 的地址为 %s", "set URL of remote %s to %s"},
+	"dryrun.init_repo":      {"在 %s 初始化 Git 仓库", "initialize a git repository in %s"},
+	"dryrun.clone":          {"克隆 %s 到 %s", "clone %s into %s"},
+	"dryrun.release":        {"在托管平台上创建发布 %s", "create forge release %s"},
+
+	// packages
+	"package.unchanged": {"⚠️ 包 %s 自 %s 以来没有新的提交", "⚠️ Package %s has no new commits since %s"},
+	"bump.unchanged":    {"自 %s 以来没有新的提交，版本保持为 %s", "No new commits since %s, version stays %s"},
+	"bump.done":         {"✓ 版本已升级为 %s（标签 %s）", "✓ Version bumped to %s (tag %s)"},
+	"bump.commits":      {"  自 %[2]s 以来有 %[1]d 个提交", "  %d commit(s) since %s"},
+	"changelog.since":   {"自 %s 以来的提交:", "Commits since %s:"},
+	"changelog.all":     {"所有提交:", "All commits:"},
+	"changelog.none":    {"没有新的提交", "No new commits"},
+
+	// Go 模块
+	"gomod.invalid":          {"标签不符合 Go 模块规则: %w", "tag does not follow Go module rules: %w"},
+	"gomod.read_failed":      {"读取 go.mod 失败: %w", "failed to read go.mod: %w"},
+	"gomod.no_module":        {"go.mod 中没有 module 声明", "go.mod has no module directive"},
+	"gomod.tag_mismatch":     {"Go 模块 %s 的标签 %s 无效，应为 %sX.Y.Z 形式（可以设置 tag_prefix，或设置 go.skip_module_check 跳过检查）", "tag %[2]s is not valid for Go module %[1]s, expected %[3]sX.Y.Z (set tag_prefix, or go.skip_module_check to skip this check)"},
+	"gomod.major_mismatch":   {"版本 %[1]s 要求模块路径以 %[3]s 结尾，go.mod 中为 %[2]s", "version %[1]s requires the module path to end with %[3]s, go.mod has %[2]s"},
+	"gomod.major_unexpected": {"版本 %s 的主版本小于 2，但模块路径 %s 带有 /v%d 后缀", "version %s is below v2 but module path %s has the /v%d suffix"},
+	"gomod.verify":           {"🔍 校验 Go 模块...", "🔍 Verifying the Go module..."},
+	"gomod.verify_failed":    {"Go 模块校验失败: %w", "Go module verification failed: %w"},
+
+	// flags
+	"flag.set":             {"临时覆盖配置项 `key=value`，可以重复使用", "override a config `key=value` for this run, repeatable"},
+	"flag.set_invalid":     {"应为 key=value 格式", "expected key=value"},
+	"flag.profile":         {"使用配置中 profiles 下的发布方案 `name`", "use the release profile `name` from profiles"},
+	"flag.wait":            {"另一个 ghc 正在修改仓库时等待，而不是立即退出", "wait when another ghc is modifying the repository"},
+	"flag.timeout":         {"等待锁的最长时间，例如 `30s`，同时启用 --wait", "maximum time to wait for the lock, e.g. `30s`; implies --wait"},
+	"flag.timeout_invalid": {"等待时间无效: %s（例如 30s、2m）", "invalid duration: %s (e.g. 30s, 2m)"},
+	"flag.output":          {"输出格式 `text|json|yaml`，json 和 yaml 只在 stdout 输出结果，错误写入 stderr", "output `text|json|yaml`; json and yaml write only results to stdout and errors to stderr"},
+	"flag.lang":            {"界面语言 `zh-CN|en`，默认根据 LANG 选择", "interface language `zh-CN|en`, chosen from LANG by default"},
+	"flag.verbose":         {"输出读取的配置、打开的仓库等调试信息", "print debug details such as config files and repositories"},
+	"flag.dry_run":         {"只输出将要执行的操作，不修改文件、仓库或远程", "print what would be done without changing files, repositories or remotes"},
+	"flag.config":          {"使用指定的项目配置 `file`", "use `file` as the project config"},
+	"flag.dir":             {"在 `dir` 中运行，如同先进入该目录", "run as if started in `dir`"},
+	"flag.dir_short":       {"在 `dir` 中运行，同 --dir", "run in `dir`, same as --dir"},
+	"flag.package":         {"选择 packages 中的包 `name`", "select the package `name` from packages"},
+	"flag.changelog_since": {"从标签 `tag` 开始，默认为最近的标签", "start from `tag`, the latest tag by default"},
+	"flag.help":            {"显示帮助信息", "show help"},
+	"flag.fix_remote":      {"把 git 远程仓库改为配置中绑定的地址", "point git remotes at the configured repository"},
+	"flag.json":            {"以 JSON 输出，与 --output json 相同", "print JSON, same as --output json"},
+	"flag.unlock_force":    {"持有锁的进程仍在运行时也删除锁文件", "remove the lock even if its holder is still running"},
+	"flag.scope_global":    {"使用用户配置", "use the user config"},
+	"flag.scope_local":     {"使用 ghc.local.yaml", "use ghc.local.yaml"},
+	"flag.scope_project":   {"使用项目配置", "use the project config"},
+	"flag.schema_output":   {"写入 `file` 而不是 stdout", "write to `file` instead of stdout"},
+	"flag.show_origin":     {"显示每个配置项的来源", "show where each key comes from"},
+	"flag.convert_to":      {"目标格式 `yaml|yml|toml|json|package.json`", "target format `yaml|yml|toml|json|package.json`"},
+	"flag.convert_force":   {"目标文件已存在时覆盖", "overwrite an existing target file"},
+	"flag.migrate_check":   {"只检查是否需要升级，不修改文件", "only check whether an upgrade is needed"},
+
+	// help
+	"help.title":               {"ghc - GitHub 配置管理工具", "ghc - GitHub configuration manager"},
+	"help.usage":               {"使用方法:", "Usage:"},
+	"help.commands":            {"命令:", "Commands:"},
+	"help.flags":               {"参数:", "Flags:"},
+	"help.global_flags":        {"全局参数:", "Global flags:"},
+	"help.exit_codes":          {"退出码:", "Exit codes:"},
+	"help.more":                {"使用 'ghc <command> --help' 查看命令的帮助", "Run 'ghc <command> --help' for help on a command"},
+	"help.cmd.init":            {"初始化项目配置", "initialize the project config"},
+	"help.cmd.bind":            {"绑定仓库地址", "bind the repository URL"},
+	"help.cmd.status":          {"查看配置、git 和发布状态", "show config, git and release state"},
+	"help.cmd.tag":             {"创建新标签", "create a tag"},
+	"help.cmd.tag_list":        {"查看所有标签", "list tags"},
+	"help.cmd.tag_checkout":    {"切换到指定版本", "check out a version"},
+	"help.cmd.bump":            {"升级配置中的版本号", "bump the version in the config"},
+	"help.cmd.changelog":       {"列出自上一个标签以来的提交", "list commits since the last tag"},
+	"help.cmd.publish":         {"发布项目到远程仓库", "publish the project to its remotes"},
+	"help.cmd.logs":            {"查看命令日志", "show command logs"},
+	"help.cmd.history":         {"查看发布历史", "show the release history"},
+	"help.cmd.history_verify":  {"检查发布记录是否与 git 标签一致", "check the release history against git tags"},
+	"help.cmd.config":          {"校验、查看和修改配置", "validate, read and change the config"},
+	"help.cmd.config_validate": {"校验配置文件", "validate a config file"},
+	"help.cmd.config_schema":   {"导出配置的 JSON Schema", "export the config JSON Schema"},
+	"help.cmd.config_get":      {"查看配置项", "show a config key"},
+	"help.cmd.config_set":      {"修改配置项，保留文件中的注释", "change a config key, keeping comments"},
+	"help.cmd.config_unset":    {"删除配置项", "remove a config key"},
+	"help.cmd.config_list":     {"列出合并后的配置项", "list the merged config"},
+	"help.cmd.config_convert":  {"转换项目配置的格式", "convert the project config to another format"},
+	"help.cmd.config_migrate":  {"把配置升级到当前版本", "upgrade the config to the current schema version"},
+	"help.cmd.unlock":          {"清除过期的仓库锁", "clear a stale repository lock"},
+	"help.cmd.lock":            {"管理仓库锁定文件 .repo.lock", "manage the .repo.lock file"},
+	"help.cmd.lock_rebuild":    {"根据 git 状态重建 .repo.lock", "rebuild .repo.lock from git"},
+	"help.cmd.completion":      {"生成 shell 补全脚本", "generate shell completion scripts"},
+	"help.cmd.completion_bash": {"生成 bash 补全脚本", "generate the bash completion script"},
+	"help.cmd.completion_zsh":  {"生成 zsh 补全脚本", "generate the zsh completion script"},
+	"help.cmd.completion_fish": {"生成 fish 补全脚本", "generate the fish completion script"},
+	"help.cmd.help":            {"显示帮助信息", "show this help"},
+	"help.long.publish": {
+		`不指定版本时使用配置文件中的版本。
+
+示例:
+  ghc publish v1.0.0                 发布版本 v1.0.0
+  ghc release                        使用配置文件中的版本发布
+  ghc publish --profile nightly      使用 nightly 方案发布
+  ghc --dry-run publish v1.0.0       只输出将要执行的操作`,
+		`Without a version, the version from the config is used.
+
+Examples:
+  ghc publish v1.0.0                 publish version v1.0.0
+  ghc release                        publish the version from the config
+  ghc publish --profile nightly      publish with the nightly profile
+  ghc --dry-run publish v1.0.0       print what would be done`,
+	},
+	"help.long.bump": {
+		`以最近的标签为基准升级版本号并写入配置，默认升级 patch。
+使用 --package 时只考虑该包的标签和修改了包目录的提交，版本写入 packages.<name>.version。`,
+		`Bumps the version from the latest tag and writes it to the config; patch by default.
+With --package only the package's tags and commits touching its path count, and the version goes to packages.<name>.version.`,
+	},
+	"help.long.config_keys": {
+		`--global 使用用户配置，--local 使用 ghc.local.yaml，--project 使用项目配置。
+数组下标写作 remotes[0].url，数组和对象的值使用 YAML 格式，例如 '["go vet ./..."]'`,
+		`--global uses the user config, --local uses ghc.local.yaml and --project uses the project config.
+Array indexes are written as remotes[0].url; arrays and objects take YAML values, e.g. '["go vet ./..."]'`,
+	},
+	"help.long.completion": {
+		`示例:
+  source <(ghc completion bash)      在当前 bash 中启用补全
+  source <(ghc completion zsh)       在当前 zsh 中启用补全
+  ghc completion fish | source       在当前 fish 中启用补全`,
+		`Examples:
+  source <(ghc completion bash)      enable completion in the current bash
+  source <(ghc completion zsh)       enable completion in the current zsh
+  ghc completion fish | source       enable completion in the current fish`,
+	},
+	"help.exit_codes_list": {
+		"  0 成功  1 其他错误  2 命令或参数错误  3 部分发布  4 配置错误\n  5 git 错误  6 编译失败  7 网络错误  8 仓库锁被占用",
+		"  0 ok  1 other error  2 usage  3 partial publish  4 config\n  5 git  6 build  7 network  8 lock busy",
+	},
+
+	// 配置模式中的说明，键为 schema. 加上配置项的路径
+	"schema.schema_version":            {"配置文件版本，旧版本的配置在加载时自动迁移", "config file version; older configs are migrated on load"},
+	"schema.repo":                      {"绑定的仓库地址", "bound repository URL"},
+	"schema.branch":                    {"默认分支", "default branch"},
+	"schema.auto_push":                 {"是否自动推送", "push automatically"},
+	"schema.build_command":             {"构建命令", "build command"},
+	"schema.version":                   {"当前版本，例如 1.2.3 或 v1.2.3", "current version, e.g. 1.2.3 or v1.2.3"},
+	"schema.tag_prefix":                {"标签前缀", "tag prefix"},
+	"schema.pre_build":                 {"预编译钩子", "pre-build hooks"},
+	"schema.pre_build.enabled":         {"是否启用预编译钩子", "run the pre-build hooks"},
+	"schema.pre_build.commands":        {"依次执行的预编译命令", "pre-build commands run in order"},
+	"schema.pre_build.script":          {"预编译脚本", "pre-build script"},
+	"schema.pre_build.steps":           {"通过 needs 声明依赖关系的预编译步骤", "pre-build steps with dependencies declared by needs"},
+	"schema.pre_build.steps[].id":      {"步骤 ID", "step ID"},
+	"schema.pre_build.steps[].run":     {"步骤命令", "step command"},
+	"schema.pre_build.steps[].needs":   {"依赖的步骤 ID", "IDs of the steps this step depends on"},
+	"schema.pre_build.steps[].timeout": {"步骤超时时间（秒）", "step timeout in seconds"},
+	"schema.pre_build.concurrency":     {"最大并发数，默认为 CPU 核数", "maximum parallel steps, defaults to the number of CPUs"},
+	"schema.pre_build.timeout":         {"默认超时时间（秒）", "default timeout in seconds"},
+	"schema.pre_build.fail_on_error":   {"预编译失败时是否停止发布", "stop publishing when the pre-build fails"},
+	"schema.remotes":                   {"发布时推送的远程仓库", "remotes to push to when publishing"},
+	"schema.remotes[].name":            {"远程仓库名称", "remote name"},
+	"schema.remotes[].url":             {"远程仓库地址", "remote URL"},
+	"schema.remotes[].role":            {"远程仓库角色", "remote role"},
+	"schema.remote_check":              {"远程仓库地址与绑定的仓库不一致时的处理方式", "what to do when a remote URL differs from the bound repository"},
+	"schema.forge":                     {"托管平台，为空时根据仓库地址识别", "hosting platform, detected from the repository URL when empty"},
+	"schema.forge_hosts":               {"自建托管平台的主机名映射", "host name mapping for self-hosted platforms"},
+	"schema.token":                     {"托管平台 API 令牌", "hosting platform API token"},
+	"schema.release":                   {"托管平台发布配置", "hosting platform release settings"},
+	"schema.release.enabled":           {"发布时是否在托管平台上创建发布", "create a release on the hosting platform when publishing"},
+	"schema.release.name":              {"发布标题，{version} 会被替换为版本号", "release title; {version} is replaced with the version"},
+	"schema.release.notes":             {"发布说明", "release notes"},
+	"schema.release.draft":             {"是否创建草稿", "create a draft"},
+	"schema.release.prerelease":        {"是否为预发布", "mark as a prerelease"},
+	"schema.release.assets":            {"要上传的附件，支持通配符", "assets to upload; globs are supported"},
+	"schema.release.api_url":           {"托管平台 API 地址", "hosting platform API URL"},
+	"schema.logs":                      {"命令日志配置", "command log settings"},
+	"schema.logs.retention":            {"保留的运行记录数量", "number of runs to keep"},
+	"schema.logs.tail_lines":           {"命令失败时输出的日志行数", "log lines to print when a command fails"},
+	"schema.go":                        {"Go 模块检查配置", "Go module check settings"},
+	"schema.go.skip_module_check":      {"不检查标签与 go.mod 中的模块路径是否一致", "do not check tags against the module path in go.mod"},
+	"schema.go.verify":                 {"构建后执行 go list -m 和 go mod verify", "run go list -m and go mod verify after building"},
+	"schema.lang":                      {"界面语言，为空时根据 LANG 等环境变量选择", "interface language; chosen from LANG and related variables when empty"},
+	"schema.profiles":                  {"命名的发布方案，通过 --profile 选择并覆盖顶层配置", "named release profiles selected with --profile, overriding the top-level config"},
+	"schema.profiles.*":                {"发布方案，可以包含除 profiles 和 packages 以外的任意配置项", "release profile; may contain any key except profiles and packages"},
+	"schema.packages":                  {"monorepo 中独立发布版本的包，通过 --package 选择", "monorepo packages with their own versions, selected with --package"},
+	"schema.packages.*":                {"包的目录、标签前缀、构建命令和版本", "package path, tag prefix, build command and version"},
+	"schema.packages.*.path":           {"包所在的目录，相对于项目根目录", "package directory, relative to the project root"},
+	"schema.packages.*.tag_prefix":     {"标签前缀，例如 api/v，为空时使用 <path>/v", "tag prefix, e.g. api/v; <path>/v when empty"},
+	"schema.packages.*.build_command":  {"构建命令，在包的目录中执行", "build command, run in the package directory"},
+	"schema.packages.*.version":        {"包的当前版本", "current package version"},
+
+	// 配置校验
+	"common.list_separator":      {"、", ", "},
+	"schema.expected_object":     {"应为对象，实际为%s", "expected an object, got %s"},
+	"schema.duplicate_key":       {"配置项重复", "duplicate key"},
+	"schema.unknown_key_suggest": {"未知配置项（是否为 %s？）", "unknown key (did you mean %s?)"},
+	"schema.unknown_key":         {"未知配置项", "unknown key"},
+	"schema.missing_required":    {"缺少必填项 %s", "missing required key %s"},
+	"schema.expected_array":      {"应为数组，实际为%s", "expected an array, got %s"},
+	"schema.expected_boolean":    {"应为布尔值 true 或 false，实际为%s", "expected true or false, got %s"},
+	"schema.expected_integer":    {"应为整数，实际为%s", "expected an integer, got %s"},
+	"schema.invalid_integer":     {"整数无效: %s", "invalid integer: %s"},
+	"schema.below_minimum":       {"不能小于 %d，实际为 %d", "must be at least %d, got %d"},
+	"schema.expected_string":     {"应为字符串，实际为%s", "expected a string, got %s"},
+	"schema.invalid_enum":        {"取值无效 %q，可选 %s", "invalid value %q, expected one of %s"},
+	"schema.invalid_version":     {"版本号格式无效 %q，应为 X.Y.Z 形式的语义化版本", "invalid version %q, expected a semantic version like X.Y.Z"},
+	"schema.invalid_repo_url":    {"仓库地址无效 %q，应包含所有者和仓库名", "invalid repository URL %q, expected an owner and a repository name"},
+	"schema.invalid_local_repo":  {"仓库地址无效 %q，应为 host/owner/name 形式的地址，本地仓库需写成绝对路径或以 ./、../、file:// 开头", "invalid repository URL %q, expected host/owner/name; local repositories must be absolute paths or start with ./, ../ or file://"},
+	"schema.invalid_url":         {"URL 无效 %q，应为 http(s) 地址", "invalid URL %q, expected an http(s) address"},
+	"schema.kind_object":         {"对象", "an object"},
+	"schema.kind_array":          {"数组", "an array"},
+	"schema.kind_boolean":        {"布尔值 %s", "the boolean %s"},
+	"schema.kind_number":         {"数字 %s", "the number %s"},
+	"schema.kind_string":         {"字符串 %q", "the string %q"},
+
+	// ghc status
+	"status.not_bound":          {"repo: （未绑定，请使用 ghc bind <repo-url> 绑定仓库）", "repo: (not bound, run ghc bind <repo-url>)"},
+	"status.prebuild_invalid":   {"pre_build: 配置无效: %s", "pre_build: invalid config: %s"},
+	"status.prebuild_steps":     {"pre_build: %d 个步骤", "pre_build: %d steps"},
+	"status.head_detached":      {"head: 分离于 %s", "head: detached at %s"},
+	"status.worktree_dirty":     {"worktree: %d 个文件有修改", "worktree: %d files changed"},
+	"status.worktree_clean":     {"worktree: 干净", "worktree: clean"},
+	"status.upstream":           {"upstream: %s（领先 %d，落后 %d）", "upstream: %s (ahead %d, behind %d)"},
+	"status.upstream_none":      {"upstream: 未跟踪", "upstream: not tracking"},
+	"status.latest_tag":         {"latest_tag: %s（此后 %d 个提交）", "latest_tag: %s (%d commits since)"},
+	"status.latest_tag_none":    {"latest_tag: 无", "latest_tag: none"},
+	"status.next_version":       {"next_version: %s（标签 %s）", "next_version: %s (tag %s)"},
+	"status.lock_ok":            {"lock: %s 与配置一致", "lock: %s matches the config"},
+	"status.lock_missing":       {"lock: %s 不存在", "lock: %s does not exist"},
+	"status.lock_drift":         {"lock: ⚠️ %s 与配置不一致", "lock: ⚠️ %s differs from the config"},
+	"status.lock_drift_field":   {"  %s: %s 中为 %s，配置中为 %s", "  %s: %s has %s, the config has %s"},
+	"status.remote_missing":     {"remote: %s 未添加到 git", "remote: %s is not added to git"},
+	"status.remote_ok":          {"remote: %s 与配置一致", "remote: %s matches the config"},
+	"status.remote_mismatch":    {"remote: ⚠️ %s 指向 %s，配置中为 %s", "remote: ⚠️ %s points to %s, the config has %s"},
+	"status.publish_none":       {"publish: 没有待发布的内容", "publish: nothing to publish"},
+	"status.publish_version":    {"版本 %s 尚未发布", "version %s is not published"},
+	"status.publish_commits":    {"%d 个提交未发布", "%d unreleased commits"},
+	"status.publish_incomplete": {"上次发布 %s 未完成（%s）", "last release %s did not finish (%s)"},
+	"status.publish_pending":    {"publish: 待发布，%s", "publish: pending, %s"},
+	"status.reason_separator":   {"，", ", "},
+	"status.remote_not_added":   {"（未添加到 git）", " (not added to git)"},
+	"status.remote_differs":     {"%s（⚠️ 与配置 %s 不一致）", "%s (⚠️ differs from the configured %s)"},
+	"status.untracked":          {"未跟踪", "not tracking"},
+	"status.in_sync":            {"已同步", "in sync"},
+	"status.ahead_behind":       {"领先 %d，落后 %d", "ahead %d, behind %d"},
+
+	// 配置路径和配置文件
+	"configpath.empty":              {"配置路径为空", "config path is empty"},
+	"configpath.invalid":            {"配置路径无效: %s", "invalid config path: %s"},
+	"configpath.not_object":         {"%s 不是对象，不能访问 %s", "%s is not an object, cannot access %s"},
+	"configpath.unknown_suggest":    {"未知配置项 %s（是否为 %s？）", "unknown config key %s (did you mean %s?)"},
+	"configpath.unknown":            {"未知配置项 %s", "unknown config key %s"},
+	"configpath.not_array":          {"%s 不是数组，不能使用下标", "%s is not an array, cannot use an index"},
+	"configpath.index_not_array":    {"不是数组，不能使用下标 %d", "not an array, cannot use index %d"},
+	"configpath.index_out_of_range": {"数组下标 %d 超出范围（当前长度 %d）", "index %d is out of range (length %d)"},
+	"configpath.parent_not_object":  {"%s 的上级不是对象", "the parent of %s is not an object"},
+	"configvalue.integer":           {"应为整数: %s", "expected an integer: %s"},
+	"configvalue.boolean":           {"应为布尔值 true 或 false: %s", "expected true or false: %s"},
+	"configvalue.yaml_array":        {"应为 YAML 格式的数组: %s", "expected a YAML array: %s"},
+	"configvalue.yaml_object":       {"应为 YAML 格式的对象: %s", "expected a YAML object: %s"},
+	"config.read_failed":            {"读取配置文件失败: %w", "failed to read the config file: %w"},
+	"config.parse_failed":           {"解析配置文件失败:\n%w", "failed to parse the config file:\n%w"},
+	"config.edit_invalid":           {"修改后的配置无效:\n%w", "the changed config is invalid:\n%w"},
+	"config.encode_failed":          {"序列化配置失败: %w", "failed to encode the config: %w"},
+	"config.write_failed":           {"保存配置文件失败: %w", "failed to save the config file: %w"},
+	"config.error_count":            {"共 %d 个错误", "%d errors"},
+	"config.valid":                  {"✓ %s 校验通过", "✓ %s is valid"},
+	"config.schema_encode_failed":   {"生成 JSON Schema 失败: %w", "failed to generate the JSON Schema: %w"},
+	"config.schema_write_failed":    {"写入 JSON Schema 失败: %w", "failed to write the JSON Schema: %w"},
+	"config.schema_written":         {"✓ 已导出 JSON Schema: %s", "✓ exported the JSON Schema: %s"},
+	"config.key_not_set":            {"配置项 %s 未设置", "config key %s is not set"},
+	"config.mkdir_failed":           {"创建配置目录失败: %w", "failed to create the config directory: %w"},
+	"config.key_not_in_file":        {"配置项 %s 未在 %s 中设置", "config key %s is not set in %s"},
+	"config.key_removed":            {"✓ 已从 %s 删除 %s", "✓ removed %[2]s from %[1]s"},
+	"config.convert_usage":          {"使用方法: ghc config convert --to <yaml|yml|toml|json|package.json> [--force]", "usage: ghc config convert --to <yaml|yml|toml|json|package.json> [--force]"},
+	"config.not_found":              {"配置文件 %s 不存在，请先运行 ghc init", "config file %s does not exist, run ghc init first"},
+	"config.convert_same":           {"项目配置已经是 %s", "the project config is already %s"},
+	"config.convert_exists":         {"%s 已存在，使用 --force 覆盖", "%s already exists, use --force to overwrite it"},
+	"config.convert_remove_failed":  {"⚠️ 已写入 %s，但删除 %s 失败: %v", "⚠️ wrote %s but failed to remove %s: %v"},
+	"config.converted":              {"✓ 已把 %s 转换为 %s", "✓ converted %s to %s"},
+	"migrate.needed":                {"✗ %s 的版本为 %d，需要升级到 %d:", "✗ %s is at version %d and needs to be upgraded to %d:"},
+	"migrate.run_hint":              {"请运行 'ghc config migrate' 升级配置", "run 'ghc config migrate' to upgrade the config"},
+	"migrate.up_to_date":            {"✓ 配置已是最新版本 %d", "✓ The config is already at version %d"},
+
+	// .repo.lock
+	"repolock.missing":                {"仓库锁定文件 %s 不存在", "repo lock file %s does not exist"},
+	"repolock.read_failed":            {"读取仓库锁定文件失败: %w", "failed to read the repo lock file: %w"},
+	"repolock.reason_empty":           {"文件为空", "the file is empty"},
+	"repolock.reason_parse":           {"解析失败: %v", "parse error: %v"},
+	"repolock.reason_no_checksum":     {"缺少校验和", "the checksum is missing"},
+	"repolock.reason_checksum":        {"校验和不匹配", "checksum mismatch"},
+	"repolock.encode_failed":          {"序列化仓库锁定信息失败: %w", "failed to encode the repo lock: %w"},
+	"repolock.write_failed":           {"保存仓库锁定文件失败: %w", "failed to save the repo lock file: %w"},
+	"repolock.corrupt":                {"仓库锁定文件 %s 已损坏（%s），可以使用 'ghc lock rebuild' 根据 git 状态重建", "repo lock file %s is corrupt (%s), run 'ghc lock rebuild' to rebuild it from git"},
+	"repolock.corrupt_short":          {"⚠️ %s 已损坏（%s）", "⚠️ %s is corrupt (%s)"},
+	"repolock.rebuild_confirm":        {"是否根据 git 状态（最新标签、当前分支、远程地址）重建？", "rebuild it from git (latest tag, current branch, remote URL)?"},
+	"repolock.rebuild_failed":         {"重建仓库锁定文件失败: %w", "failed to rebuild the repo lock file: %w"},
+	"repolock.rebuilt":                {"✓ 已重建 %s", "✓ rebuilt %s"},
+	"repolock.rebuilt_repo":           {"  仓库: %s", "  repo: %s"},
+	"repolock.rebuilt_branch":         {"  分支: %s", "  branch: %s"},
+	"repolock.rebuilt_version":        {"  版本: %s", "  version: %s"},
+	"migrate.v1_prebuild_steps":       {"把 pre_build.script 和 pre_build.commands 转换为 pre_build.steps", "convert pre_build.script and pre_build.commands to pre_build.steps"},
+	"migrate.invalid_version":         {"schema_version 无效: %s", "invalid schema_version: %s"},
+	"migrate.too_new":                 {"配置版本 %d 高于当前 ghc 支持的版本 %d，请升级 ghc", "config version %d is newer than version %d supported by this ghc, upgrade ghc"},
+	"migrate.step_failed":             {"从版本 %d 升级失败: %w", "upgrade from version %d failed: %w"},
+	"migrate.backup_failed":           {"备份配置文件失败: %w", "failed to back up the config file: %w"},
+	"migrate.migrated":                {"已把 %s 从版本 %d 升级到 %d（备份: %s）", "Upgraded %s from version %d to %d (backup: %s)"},
+	"config.no_user_dir":              {"无法确定用户配置目录", "cannot determine the user config directory"},
+	"config.invalid_file":             {"配置文件校验失败:\n%w", "config file validation failed:\n%w"},
+	"config.invalid":                  {"配置校验失败:\n%w", "config validation failed:\n%w"},
+	"config.decode_failed":            {"解析配置失败: %w", "failed to decode the config: %w"},
+	"config.env_invalid":              {"环境变量 %s: %w", "environment variable %s: %w"},
+	"config.set_flag_invalid":         {"--set 参数应为 key=value 形式: %s", "--set expects key=value: %s"},
+	"profile.undefined":               {"未定义发布方案 %s，请在配置的 profiles 中添加", "profile %s is not defined, add it under profiles in the config"},
+	"profile.undefined_choices":       {"未定义发布方案 %s（可选: %s）", "profile %s is not defined (available: %s)"},
+	"package.undefined":               {"未定义包 %s，请在配置的 packages 中添加", "package %s is not defined, add it under packages in the config"},
+	"package.undefined_choices":       {"未定义包 %s（可选: %s）", "package %s is not defined (available: %s)"},
+	"config.unsupported_format":       {"不支持的配置文件格式: %s（可选 .yaml、.yml、.toml、.json）", "unsupported config file format: %s (use .yaml, .yml, .toml or .json)"},
+	"config.manifest_not_object":      {"应为 JSON 对象", "expected a JSON object"},
+	"config.manifest_missing_section": {"缺少 ghc 字段", "the ghc field is missing"},
+	"config.manifest_read_failed":     {"读取 %s 失败: %w", "failed to read %s: %w"},
+	"config.manifest_file_not_object": {"%s 应为 JSON 对象", "%s should contain a JSON object"},
+	"config.json_unsupported_node":    {"无法转换为 JSON 的节点（第 %d 行）", "cannot convert the node on line %d to JSON"},
+	"config.toml_null":                {"TOML 不支持空值", "TOML does not support null values"},
+
+	// 运行日志
+	"logs.state_dir_failed":   {"创建 %s 目录失败: %w", "failed to create the %s directory: %w"},
+	"logs.create_file_failed": {"创建 %s 失败: %w", "failed to create %s: %w"},
+	"logs.dir_failed":         {"创建日志目录失败: %w", "failed to create the log directory: %w"},
+	"logs.rotate_failed":      {"⚠️ 清理旧日志失败: %v", "⚠️ failed to remove old logs: %v"},
+	"logs.list_failed":        {"读取日志目录失败: %w", "failed to read the log directory: %w"},
+	"logs.open_failed":        {"⚠️ 创建日志文件失败: %v", "⚠️ failed to create the log file: %v"},
+	"logs.tail_failed":        {"⚠️ 读取日志失败: %v", "⚠️ failed to read the log: %v"},
+	"logs.tail_header":        {"---- %s（最后 %d 行）----", "---- %s (last %d lines) ----"},
+	"logs.read_failed":        {"读取日志失败: %w", "failed to read the logs: %w"},
+	"logs.read_log_failed":    {"读取日志失败: %v", "failed to read the log: %v"},
+	"logs.none":               {"暂无运行日志", "No run logs yet"},
+	"logs.runs":               {"运行记录:", "Runs:"},
+	"logs.run_entry":          {"  %s  (%d 个日志)", "  %s  (%d logs)"},
+	"logs.hint":               {"使用 'ghc logs <run-id>' 查看某次运行的日志，'latest' 表示最近一次", "Run 'ghc logs <run-id>' to show the logs of a run; 'latest' is the most recent one"},
+	"logs.run_missing":        {"运行记录不存在: %s", "run not found: %s"},
+	"logs.step_missing":       {"运行记录 %s 中没有步骤 %s 的日志", "run %s has no log for step %s"},
+	"logs.invalid_name":       {"无效的运行 ID 或步骤名: %s", "invalid run ID or step name: %s"},
+
+	// 仓库锁
+	"lock.holder":              {"PID %d，主机 %s，命令 %s，开始于 %s", "PID %d, host %s, command %s, started at %s"},
+	"lock.busy":                {"另一个 ghc 进程正在修改仓库", "another ghc process is changing the repository"},
+	"lock.busy_stale":          {"另一个 ghc 进程正在修改仓库（锁文件中的记录已过期: %s），可以使用 --wait 等待", "another ghc process is changing the repository (the lock record is stale: %s), use --wait to wait for it"},
+	"lock.busy_holder":         {"另一个 ghc 进程正在修改仓库（%s），可以使用 --wait 等待", "another ghc process is changing the repository (%s), use --wait to wait for it"},
+	"lock.wait_timeout":        {"等待锁超时（%s），另一个 ghc 进程仍在修改仓库", "timed out after %s waiting for the lock, another ghc process is still changing the repository"},
+	"lock.wait_timeout_holder": {"等待锁超时（%s），另一个 ghc 进程仍在修改仓库（%s）", "timed out after %s waiting for the lock, another ghc process is still changing the repository (%s)"},
+	"lock.waiting_holder":      {"等待另一个 ghc 进程结束（%s）...", "waiting for another ghc process to finish (%s)..."},
+	"lock.waiting":             {"等待另一个 ghc 进程结束...", "waiting for another ghc process to finish..."},
+	"lock.open_failed":         {"打开锁文件失败: %w", "failed to open the lock file: %w"},
+	"lock.write_failed":        {"写入锁文件失败: %w", "failed to write the lock file: %w"},
+	"lock.flock_failed":        {"加锁失败: %w", "failed to lock the file: %w"},
+	"lock.none":                {"当前没有锁", "there is no lock"},
+	"lock.remove_failed":       {"删除锁文件失败: %w", "failed to remove the lock file: %w"},
+	"lock.forced_holder":       {"✓ 已强制清除锁（%s）", "✓ forcibly cleared the lock (%s)"},
+	"lock.forced":              {"✓ 已强制清除锁", "✓ forcibly cleared the lock"},
+	"lock.stale_but_held":      {"锁文件记录的进程已经退出（%s），但锁仍被另一个进程持有", "the process in the lock record has exited (%s), but another process still holds the lock"},
+	"lock.force_hint":          {"如果确认该进程已经不存在，请使用 'ghc unlock --force'", "if you are sure no such process exists, run 'ghc unlock --force'"},
+	"lock.cleared":             {"✓ 已清除已退出进程留下的锁记录（%s）", "✓ cleared the lock record left by an exited process (%s)"},
+	"lock.not_held":            {"当前没有 ghc 进程持有锁", "no ghc process holds the lock"},
+
+	// 错误类别和其他通用消息
+	"error.usage":          {"命令或参数错误", "usage error"},
+	"error.config":         {"配置错误", "config error"},
+	"error.git":            {"git 操作失败", "git operation failed"},
+	"error.build":          {"编译失败", "build failed"},
+	"error.network":        {"网络请求失败", "network request failed"},
+	"error.partial":        {"部分发布", "partially published"},
+	"signal.received":      {"收到信号 %v", "received signal %v"},
+	"signal.stopping":      {"收到信号 %v，正在停止...（再次按 Ctrl-C 强制退出）", "received signal %v, stopping... (press Ctrl-C again to force quit)"},
+	"file.rename_failed":   {"重命名 %s 失败: %w", "failed to rename %s: %w"},
+	"version.invalid":      {"版本号无效: %s（应为 1.2.3 形式）", "invalid version: %s (expected a form like 1.2.3)"},
+	"version.invalid_part": {"升级类型无效: %s（可选 major、minor、patch）", "invalid bump part: %s (use major, minor or patch)"},
+	"output.invalid":       {"--output 无效: %s（可选 text、json、yaml）", "invalid --output: %s (use text, json or yaml)"},
+	"output.encode_failed": {"序列化结果失败: %w", "failed to encode the result: %w"},
+	"cli.invalid_lang":     {"--lang 无效: %s（可选 %s、%s）", "invalid --lang: %s (use %s or %s)"},
+
+	// 预编译步骤
+	"prebuild.status_succeeded": {"✓ 成功", "✓ ok"},
+	"prebuild.status_failed":    {"✗ 失败", "✗ failed"},
+	"prebuild.status_ignored":   {"⚠️ 失败（已忽略）", "⚠️ failed (ignored)"},
+	"prebuild.status_canceled":  {"✗ 已取消", "✗ canceled"},
+	"prebuild.status_skipped":   {"- 跳过", "- skipped"},
+	"prebuild.status_pending":   {"未执行", "not run"},
+	"prebuild.missing_id":       {"第 %d 个预编译步骤缺少 id", "pre-build step %d has no id"},
+	"prebuild.missing_run":      {"预编译步骤 %s 缺少 run 命令", "pre-build step %s has no run command"},
+	"prebuild.duplicate_id":     {"预编译步骤 id 重复: %s", "duplicate pre-build step id: %s"},
+	"prebuild.unknown_need":     {"预编译步骤 %s 依赖了不存在的步骤: %s", "pre-build step %s needs an unknown step: %s"},
+	"prebuild.self_need":        {"预编译步骤 %s 不能依赖自身", "pre-build step %s cannot depend on itself"},
+	"prebuild.cycle":            {"预编译步骤存在循环依赖: %s", "pre-build steps have a dependency cycle: %s"},
+	"prebuild.step_failed":      {"预编译步骤 %s 执行失败: %w", "pre-build step %s failed: %w"},
+	"prebuild.canceled":         {"预编译已取消: %v", "pre-build canceled: %v"},
+	"prebuild.summary":          {"预编译耗时汇总:", "Pre-build timings:"},
+	"prebuild.total":            {"总耗时: %s（并发数 %d）", "Total: %s (concurrency %d)"},
+
+	// 远程仓库
+	"remotes.missing_name":     {"远程仓库缺少 name", "a remote has no name"},
+	"remotes.missing_url":      {"远程仓库 %s 缺少 url", "remote %s has no url"},
+	"remotes.duplicate":        {"远程仓库名称重复: %s", "duplicate remote name: %s"},
+	"remotes.invalid_role":     {"远程仓库 %s 的 role 无效: %s（可选 primary、mirror、upstream）", "remote %s has an invalid role: %s (use primary, mirror or upstream)"},
+	"remotes.multiple_primary": {"只能有一个 primary 远程仓库", "only one remote can be primary"},
+	"remotes.lock_mismatch":    {"%s 绑定的仓库为 %s，但 %s 中 %s 的地址为 %s", "%s is bound to %s, but %s has %s at %s"},
+	"remotes.git_mismatch":     {"%s 中 %s 的地址为 %s，但 git 远程仓库 %[2]s 实际指向 %[4]s", "%s has %s at %s, but the git remote %[2]s points to %[4]s"},
+	"remotes.mismatch_header":  {"⚠️ 远程仓库地址与绑定的仓库不一致:", "⚠️ Remote URLs differ from the bound repository:"},
+	"remotes.fix_hint":         {"使用 'ghc bind --fix-remote' 把远程仓库改为配置中的地址", "Run 'ghc bind --fix-remote' to point the remotes at the configured URLs"},
+	"remotes.blocked":          {"远程仓库地址不一致，已阻止推送（可设置 remote_check: warn 改为只警告）", "remote URLs differ, push blocked (set remote_check: warn to only warn)"},
+	"remotes.invalid_check":    {"remote_check 的值无效: %s（可选 block、warn）", "invalid remote_check: %s (use block or warn)"},
+	"remotes.adding":           {"添加远程仓库 %s: %s", "Adding remote %s: %s"},
+	"remotes.updating":         {"修改远程仓库 %s: %s -> %s", "Updating remote %s: %s -> %s"},
+	"remotes.updating_lock":    {"更新 %s 绑定的仓库: %s", "Updating the repository bound in %s: %s"},
+	"remotes.canceled":         {"已取消: %v", "canceled: %v"},
+
+	// 托管平台
+	"forge.unsupported":           {"不支持的托管平台: %s（可选 github、gitlab、gitea、bitbucket）", "unsupported forge: %s (use github, gitlab, gitea or bitbucket)"},
+	"forge.invalid_host_forge":    {"forge_hosts 中 %s 的托管平台无效: %s", "invalid forge for %s in forge_hosts: %s"},
+	"forge.local_repo":            {"本地仓库不支持创建发布", "releases are not supported for local repositories"},
+	"forge.missing_owner":         {"仓库地址缺少所有者: %s", "repository URL has no owner: %s"},
+	"forge.unknown_host":          {"无法识别 %s 的托管平台，请在配置中设置 forge 或 forge_hosts", "cannot detect the forge for %s, set forge or forge_hosts in the config"},
+	"forge.no_releases":           {"托管平台 %s 暂不支持创建发布", "creating releases is not supported on %s yet"},
+	"forge.encode_failed":         {"序列化请求失败: %w", "failed to encode request: %w"},
+	"forge.new_request_failed":    {"创建请求失败: %w", "failed to create request: %w"},
+	"forge.request_failed":        {"%s %s 请求失败: %w", "%s %s failed: %w"},
+	"forge.read_failed":           {"读取响应失败: %w", "failed to read response: %w"},
+	"forge.decode_failed":         {"解析响应失败: %w", "failed to parse response: %w"},
+	"forge.http_error":            {"%s %s 返回 %s: %s", "%s %s returned %s: %s"},
+	"forge.get_release_failed":    {"查询 %s 发布失败: %w", "failed to look up %s release: %w"},
+	"forge.create_release_failed": {"创建 %s 发布失败: %w", "failed to create %s release: %w"},
+	"forge.missing_upload_url":    {"发布 %s 缺少上传地址", "release %s has no upload URL"},
+	"forge.open_asset_failed":     {"打开附件失败: %w", "failed to open asset: %w"},
+	"forge.stat_asset_failed":     {"读取附件信息失败: %w", "failed to stat asset: %w"},
+	"forge.upload_failed":         {"上传附件 %s 失败: %w", "failed to upload asset %s: %w"},
+	"forge.upload_package_failed": {"上传附件 %s 到软件包仓库失败: %w", "failed to upload asset %s to the package registry: %w"},
+	"forge.link_asset_failed":     {"添加附件链接 %s 失败: %w", "failed to link asset %s: %w"},
+
+	// 仓库地址
+	"repourl.empty":              {"仓库地址为空", "repository URL is empty"},
+	"repourl.invalid":            {"无效的仓库地址 %s: %w", "invalid repository URL %s: %w"},
+	"repourl.unsupported_scheme": {"不支持的仓库地址协议: %s", "unsupported repository URL scheme: %s"},
+	"repourl.missing_host":       {"仓库地址缺少主机名: %s", "repository URL has no host: %s"},
+
+	// 托管平台发布
+	"release.not_bound":       {"未配置远程仓库地址，请先使用 'ghc bind <repo-url>' 绑定仓库", "no repository configured, run 'ghc bind <repo-url>' first"},
+	"release.no_token":        {"⚠️ 未找到 %s 的 API 令牌，请设置 token 或环境变量 GHC_TOKEN", "⚠️ No API token for %s, set token or the GHC_TOKEN environment variable"},
+	"release.invalid_pattern": {"附件模式无效 %s: %w", "invalid asset pattern %s: %w"},
+	"release.no_match":        {"附件模式 %s 没有匹配任何文件", "asset pattern %s matched no files"},
+	"release.exists":          {"%s 上已存在发布 %s，继续上传附件", "Release %[2]s already exists on %[1]s, uploading assets"},
+	"release.creating":        {"在 %s 上创建发布 %s", "Creating release %[2]s on %[1]s"},
+	"release.uploading":       {"上传附件: %s", "Uploading asset: %s"},
+
+	// 发布历史
+	"history.record_failed":   {"⚠️ 无法记录发布历史: %v", "⚠️ Could not record release history: %v"},
+	"history.checksum_failed": {"⚠️ 无法计算附件校验和: %v", "⚠️ Could not checksum asset: %v"},
+	"history.none":            {"暂无发布记录", "No releases recorded"},
+	"history.tag_missing":     {"  ✗ %s: 标签 %s 不存在", "  ✗ %s: tag %s does not exist"},
+	"history.tag_moved":       {"  ✗ %s: 标签 %s 指向 %s，发布时为 %s", "  ✗ %s: tag %s points to %s, was %s at release"},
+	"history.mismatch":        {"%d 条发布记录与 git 标签不一致", "%d release records do not match the git tags"},
+	"history.consistent":      {"✓ %d 条发布记录与 git 标签一致", "✓ %d release records match the git tags"},
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// formatVerbPattern 匹配 fmt 的格式动词，例如 %s、%[2]d、%-10s、%%
+var formatVerbPattern = regexp.MustCompile(`%(?:\[(\d+)\])?[-+# 0]*(?:\d+)?(?:\.\d+)?([a-zA-Z%])`)
+
+// formatVerbs 返回每个参数位置使用的格式动词，%[n] 形式的显式位置按 n 计算
+func formatVerbs(format string) map[int]string {
+	verbs := make(map[int]string)
+	next := 1
+	for _, m := range formatVerbPattern.FindAllStringSubmatch(format, -1) {
+		if m[2] == "%" {
+			continue
+		}
+		if m[1] != "" {
+			next, _ = strconv.Atoi(m[1])
+		}
+		verbs[next] = m[2]
+		next++
+	}
+	return verbs
+}
+
+func TestMessagesComplete(t *testing.T) {
+	for key, m := range messages {
+		if m.zh == "" {
+			t.Errorf("%s: missing zh-CN text", key)
+		}
+		if m.en == "" {
+			t.Errorf("%s: missing en text", key)
+		}
+		if strings.IndexFunc(m.en, isHan) >= 0 {
+			t.Errorf("%s: en text contains Chinese: %q", key, m.en)
+		}
+		if zh, en := formatVerbs(m.zh), formatVerbs(m.en); !reflect.DeepEqual(zh, en) {
+			t.Errorf("%s: format verbs differ: zh-CN %v, en %v", key, zh, en)
+		}
+	}
+}
+
+// TestMessageKeysExist 扫描源码中 msg("…") 的调用和命令定义中的 Short、Long，确认使用的键都在 messages 中
+func TestMessageKeysExist(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	checked := 0
+	check := func(node ast.Node, expr ast.Expr) {
+		switch lit := expr.(type) {
+		case *ast.BasicLit:
+			if lit.Kind != token.STRING {
+				return
+			}
+			key, _ := strconv.Unquote(lit.Value)
+			checked++
+			if _, ok := messages[key]; !ok {
+				t.Errorf("%s: message key %q is not in the catalog", fset.Position(node.Pos()), key)
+			}
+		case *ast.BinaryExpr:
+			// "help.cmd.completion_" + shell 这样的拼接至少要有一个以该前缀开头的键
+			prefix, ok := lit.X.(*ast.BasicLit)
+			if !ok || lit.Op != token.ADD || prefix.Kind != token.STRING {
+				return
+			}
+			value, _ := strconv.Unquote(prefix.Value)
+			checked++
+			for key := range messages {
+				if strings.HasPrefix(key, value) {
+					return
+				}
+			}
+			t.Errorf("%s: no message key starts with %q", fset.Position(node.Pos()), value)
+		}
+	}
+
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.CallExpr:
+				if ident, ok := n.Fun.(*ast.Ident); ok && ident.Name == "msg" && len(n.Args) > 0 {
+					check(n, n.Args[0])
+					checkWrapVerb(t, fset, n)
+				}
+			case *ast.KeyValueExpr:
+				if ident, ok := n.Key.(*ast.Ident); ok && (ident.Name == "Short" || ident.Name == "Long") {
+					check(n, n.Value)
+				}
+			}
+			return true
+		})
+	}
+	if checked == 0 {
+		t.Fatal("no message keys found in the sources")
+	}
+}
+
+// checkWrapVerb 确认带参数的 msg 调用不使用含 %w 的文本：msg 用 fmt.Sprintf 格式化，%w 只能交给 fmt.Errorf
+func checkWrapVerb(t *testing.T, fset *token.FileSet, call *ast.CallExpr) {
+	t.Helper()
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || len(call.Args) == 1 {
+		return
+	}
+	key, _ := strconv.Unquote(lit.Value)
+	if m, ok := messages[key]; ok && strings.Contains(m.zh, "%w") {
+		t.Errorf("%s: message %q uses %%w but is formatted by msg", fset.Position(call.Pos()), key)
+	}
+}
+
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// TestNoHardcodedChinese 确认 i18n.go 之外的源码不再直接写中文文本，用户可见的文字都应放进 messages
+func TestNoHardcodedChinese(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if name == "i18n.go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			lit, ok := node.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			if value, _ := strconv.Unquote(lit.Value); strings.IndexFunc(value, isHan) >= 0 {
+				t.Errorf("%s: hardcoded Chinese text %s, move it into messages", fset.Position(lit.Pos()), lit.Value)
+			}
+			return true
+		})
+	}
+}
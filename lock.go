@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RunLockFile 防止多个 ghc 同时修改仓库的咨询锁，内容为持有者信息
+var RunLockFile = filepath.Join(StateDir, "ghc.lock")
+
+// lockPollInterval 等待锁时的轮询间隔
+const lockPollInterval = 200 * time.Millisecond
+
+// errLockBusy 锁已被其他进程持有
+var errLockBusy = errors.New("lock busy")
+
+// LockOptions 获取锁的方式
+type LockOptions struct {
+	Wait    bool          // 锁被占用时等待
+	Timeout time.Duration // 等待的最长时间，0 表示一直等待
+}
+
+// lockOptions 通过 --wait 和 --timeout 指定的等待方式
+var lockOptions LockOptions
+
+// lockHolder 写入锁文件的持有者信息
+type lockHolder struct {
+	PID       int       `yaml:"pid" json:"pid"`
+	Host      string    `yaml:"host" json:"host"`
+	Command   string    `yaml:"command" json:"command"`
+	StartedAt time.Time `yaml:"started_at" json:"started_at"`
+}
+
+func (h *lockHolder) String() string {
+	return msg("lock.holder", h.PID, h.Host, h.Command, h.StartedAt.Format("2006-01-02 15:04:05"))
+}
+
+// stale 判断持有者是否为本机上已经退出的进程。
+// 进程退出时系统会释放它的文件锁，锁仍被占用而记录已过期说明另一个进程刚获取锁、还没有写入自己的信息，
+// 因此过期只作为提示，不能据此删除锁文件
+func (h *lockHolder) stale() bool {
+	host, _ := os.Hostname()
+	return h.PID > 0 && h.Host == host && !processAlive(h.PID)
+}
+
+// LockBusyError 锁被其他 ghc 进程持有
+type LockBusyError struct {
+	Holder *lockHolder
+}
+
+func (e *LockBusyError) Error() string {
+	if e.Holder == nil {
+		return msg("lock.busy")
+	}
+	if e.Holder.stale() {
+		return msg("lock.busy_stale", e.Holder)
+	}
+	return msg("lock.busy_holder", e.Holder)
+}
+
+// runLock 已获取的锁
+type runLock struct {
+	file *os.File
+}
+
+// acquireRunLock 获取仓库锁，锁被占用时按 opts 等待。
+// 持有者退出后系统会释放文件锁，之后直接获取锁并覆盖它留下的记录，不需要删除锁文件
+func acquireRunLock(ctx context.Context, command string, opts LockOptions) (*runLock, error) {
+	if err := ensureStateDir(); err != nil {
+		return nil, err
+	}
+
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+	announced := false
+
+	for {
+		lock, err := tryAcquireRunLock(command)
+		if err == nil {
+			debugf(msg("verbose.lock_acquired"), RunLockFile)
+			return lock, nil
+		}
+		if err != errLockBusy {
+			return nil, err
+		}
+
+		holder := readLockHolder()
+		if !opts.Wait {
+			return nil, &LockBusyError{Holder: holder}
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			if holder == nil {
+				return nil, fmt.Errorf(msg("lock.wait_timeout"), opts.Timeout)
+			}
+			return nil, fmt.Errorf(msg("lock.wait_timeout_holder"), opts.Timeout, holder)
+		}
+		if !announced {
+			if holder != nil {
+				fmt.Println(msg("lock.waiting_holder", holder))
+			} else {
+				fmt.Println(msg("lock.waiting"))
+			}
+			announced = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// tryAcquireRunLock 尝试获取一次锁，成功后写入持有者信息
+func tryAcquireRunLock(command string) (*runLock, error) {
+	file, err := os.OpenFile(RunLockFile, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf(msg("lock.open_failed"), err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// 打开文件后锁文件可能被 ghc unlock --force 删除，此时锁住的是已删除的文件
+	info, statErr := file.Stat()
+	current, pathErr := os.Stat(RunLockFile)
+	if statErr != nil || pathErr != nil || !os.SameFile(info, current) {
+		unlockFile(file)
+		file.Close()
+		return tryAcquireRunLock(command)
+	}
+
+	host, _ := os.Hostname()
+	data, err := yaml.Marshal(&lockHolder{PID: os.Getpid(), Host: host, Command: command, StartedAt: time.Now()})
+	if err == nil {
+		file.Truncate(0)
+		_, err = file.WriteAt(data, 0)
+	}
+	if err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, fmt.Errorf(msg("lock.write_failed"), err)
+	}
+	return &runLock{file: file}, nil
+}
+
+// Release 释放锁，清空持有者信息
+func (l *runLock) Release() {
+	if l == nil || l.file == nil {
+		return
+	}
+	l.file.Truncate(0)
+	unlockFile(l.file)
+	l.file.Close()
+	l.file = nil
+}
+
+// readLockHolder 读取锁文件中的持有者信息，没有信息时返回 nil
+func readLockHolder() *lockHolder {
+	data, err := ioutil.ReadFile(RunLockFile)
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+	var holder lockHolder
+	if err := yaml.Unmarshal(data, &holder); err != nil {
+		return nil
+	}
+	return &holder
+}
+
+// unlockResult ghc unlock 的结果
+type unlockResult struct {
+	Cleared bool        `json:"cleared" yaml:"cleared"`                   // 是否清除了锁文件或其中的记录
+	Holder  *lockHolder `json:"holder,omitempty" yaml:"holder,omitempty"` // 被清除的持有者
+}
+
+// handleUnlock 清除已退出进程留下的持有者信息，--force 在锁仍被占用时也删除锁文件
+func handleUnlock(force bool) error {
+	if !fileExists(RunLockFile) {
+		return emitResult(&unlockResult{}, func() { fmt.Println(msg("lock.none")) })
+	}
+
+	holder := readLockHolder()
+	if force {
+		if !dryRunSkip(msg("dryrun.remove", RunLockFile)) {
+			if err := os.Remove(RunLockFile); err != nil {
+				return fmt.Errorf(msg("lock.remove_failed"), err)
+			}
+		}
+		return emitResult(&unlockResult{Cleared: true, Holder: holder}, func() {
+			if holder != nil {
+				fmt.Println(msg("lock.forced_holder", holder))
+			} else {
+				fmt.Println(msg("lock.forced"))
+			}
+		})
+	}
+
+	lock, err := tryAcquireRunLock("unlock")
+	if err == errLockBusy {
+		// 锁仍被占用时不删除锁文件，即使记录的进程已经退出
+		if holder != nil && holder.stale() {
+			fmt.Println(msg("lock.stale_but_held", holder))
+		}
+		fmt.Println(msg("lock.force_hint"))
+		return &LockBusyError{Holder: holder}
+	}
+	if err != nil {
+		return err
+	}
+
+	// 获取到锁说明没有进程持有锁，释放时清空已退出进程留下的记录
+	lock.Release()
+	if holder != nil {
+		return emitResult(&unlockResult{Cleared: true, Holder: holder}, func() {
+			fmt.Println(msg("lock.cleared", holder))
+		})
+	}
+	return emitResult(&unlockResult{}, func() { fmt.Println(msg("lock.not_held")) })
+}
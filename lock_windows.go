@@ -0,0 +1,50 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset 加锁的字节位置，位于文件内容之外，这样其他进程仍然可以读取持有者信息
+const lockOffset = 1 << 30
+
+// lockFile 以非阻塞方式对文件加排他锁，锁被占用时返回 errLockBusy
+func lockFile(file *os.File) error {
+	overlapped := &windows.Overlapped{Offset: lockOffset}
+	err := windows.LockFileEx(windows.Handle(file.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, overlapped)
+	if err == windows.ERROR_LOCK_VIOLATION || err == windows.ERROR_IO_PENDING {
+		return errLockBusy
+	}
+	if err != nil {
+		return fmt.Errorf(msg("lock.flock_failed"), err)
+	}
+	return nil
+}
+
+// unlockFile 释放文件锁
+func unlockFile(file *os.File) error {
+	overlapped := &windows.Overlapped{Offset: lockOffset}
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, overlapped)
+}
+
+// processAlive 判断本机上的进程是否仍在运行
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// 没有权限查询时视为仍在运行
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return true
+	}
+	const stillActive = 259
+	return code == stillActive
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	StateDir = ".ghc"
+	LogsDir  = ".ghc/logs"
+
+	defaultLogRetention = 20
+	defaultLogTailLines = 20
+	logRunIDLayout      = "20060102-150405"
+)
+
+// LogsConfig 命令日志配置
+type LogsConfig struct {
+	Retention int `yaml:"retention,omitempty"`  // 保留的运行记录数量
+	TailLines int `yaml:"tail_lines,omitempty"` // 命令失败时输出的日志行数
+}
+
+// LogRun 一次 ghc 运行的日志目录
+type LogRun struct {
+	ID        string
+	Dir       string
+	tailLines int
+
+	mu    sync.Mutex
+	steps map[string]int
+}
+
+// currentLogRun 当前运行的日志记录，为 nil 时命令输出不落盘
+var currentLogRun *LogRun
+
+// ensureStateDir 创建 .ghc 目录，目录自带 .gitignore，避免日志和锁文件被 git add . 提交
+func ensureStateDir() error {
+	if err := os.MkdirAll(StateDir, 0755); err != nil {
+		return fmt.Errorf(msg("logs.state_dir_failed"), StateDir, err)
+	}
+	ignoreFile := filepath.Join(StateDir, ".gitignore")
+	if !fileExists(ignoreFile) {
+		if err := ioutil.WriteFile(ignoreFile, []byte("*\n"), 0644); err != nil {
+			return fmt.Errorf(msg("logs.create_file_failed"), ignoreFile, err)
+		}
+	}
+	return nil
+}
+
+// startLogRun 创建新的运行日志目录并清理过期记录，使用 --dry-run 时不记录日志，返回 nil
+func startLogRun(config LogsConfig) (*LogRun, error) {
+	if dryRun {
+		return nil, nil
+	}
+	if err := ensureStateDir(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(LogsDir, 0755); err != nil {
+		return nil, fmt.Errorf(msg("logs.dir_failed"), err)
+	}
+
+	id := time.Now().Format(logRunIDLayout)
+	dir := filepath.Join(LogsDir, id)
+	for i := 2; fileExists(dir); i++ {
+		dir = filepath.Join(LogsDir, fmt.Sprintf("%s-%d", id, i))
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return nil, fmt.Errorf(msg("logs.dir_failed"), err)
+	}
+
+	retention := config.Retention
+	if retention <= 0 {
+		retention = defaultLogRetention
+	}
+	if err := rotateLogRuns(retention); err != nil {
+		fmt.Println(msg("logs.rotate_failed", err))
+	}
+
+	tailLines := config.TailLines
+	if tailLines <= 0 {
+		tailLines = defaultLogTailLines
+	}
+
+	return &LogRun{
+		ID:        filepath.Base(dir),
+		Dir:       dir,
+		tailLines: tailLines,
+		steps:     make(map[string]int),
+	}, nil
+}
+
+// rotateLogRuns 只保留最新的 retention 次运行日志
+func rotateLogRuns(retention int) error {
+	runs, err := listLogRuns()
+	if err != nil {
+		return err
+	}
+	if len(runs) <= retention {
+		return nil
+	}
+	for _, run := range runs[:len(runs)-retention] {
+		if err := os.RemoveAll(filepath.Join(LogsDir, run)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listLogRuns 按时间顺序列出所有运行记录 ID
+func listLogRuns() ([]string, error) {
+	entries, err := ioutil.ReadDir(LogsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(msg("logs.list_failed"), err)
+	}
+
+	var runs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			runs = append(runs, entry.Name())
+		}
+	}
+	sort.Strings(runs)
+	return runs, nil
+}
+
+var unsafeStepChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// stepLogName 根据步骤名生成日志文件名，同名步骤自动追加序号
+func (r *LogRun) stepLogName(step string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := strings.Trim(unsafeStepChars.ReplaceAllString(step, "-"), "-")
+	if name == "" {
+		name = "step"
+	}
+	r.steps[name]++
+	if n := r.steps[name]; n > 1 {
+		name = fmt.Sprintf("%s-%d", name, n)
+	}
+	return name + ".log"
+}
+
+// commandLog 单个命令的日志文件
+type commandLog struct {
+	path      string
+	file      *os.File
+	start     time.Time
+	tailLines int
+}
+
+// openCommandLog 为命令创建日志文件，未启用日志或创建失败时返回 nil
+func openCommandLog(step, command string) *commandLog {
+	run := currentLogRun
+	if run == nil {
+		return nil
+	}
+
+	path := filepath.Join(run.Dir, run.stepLogName(step))
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Println(msg("logs.open_failed", err))
+		return nil
+	}
+
+	log := &commandLog{path: path, file: file, start: time.Now(), tailLines: run.tailLines}
+	fmt.Fprintf(file, "# command: %s\n# started: %s\n", command, log.start.Format(time.RFC3339))
+	return log
+}
+
+// stepNameForCommand 根据命令生成步骤名，例如 "git push -u origin main" -> "git-push"
+func stepNameForCommand(command string) string {
+	parts := strings.Fields(command)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	for i, part := range parts {
+		parts[i] = filepath.Base(part)
+	}
+	return strings.Join(parts, "-")
+}
+
+// Tee 返回同时写入终端和日志文件的 Writer
+func (l *commandLog) Tee(w io.Writer) io.Writer {
+	if l == nil {
+		return w
+	}
+	return io.MultiWriter(w, l.file)
+}
+
+// Finish 关闭日志文件，命令失败时输出日志末尾若干行
+func (l *commandLog) Finish(runErr error) {
+	if l == nil {
+		return
+	}
+
+	status := "ok"
+	if runErr != nil {
+		status = runErr.Error()
+	}
+	fmt.Fprintf(l.file, "# finished: %s (%s)\n# status: %s\n",
+		time.Now().Format(time.RFC3339), time.Since(l.start).Round(time.Millisecond), status)
+	l.file.Close()
+
+	if runErr != nil {
+		printLogTail(l.path, l.tailLines)
+	}
+}
+
+// printLogTail 输出日志文件的最后 n 行
+func printLogTail(path string, n int) {
+	lines, err := readLastLines(path, n)
+	if err != nil {
+		fmt.Println(msg("logs.tail_failed", err))
+		return
+	}
+
+	fmt.Println(msg("logs.tail_header", path, len(lines)))
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	fmt.Println("----")
+}
+
+// readLastLines 读取文件的最后 n 行
+func readLastLines(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// handleLogs 处理日志查看命令
+func handleLogs(args []string) error {
+	runs, err := listLogRuns()
+	if err != nil {
+		return fmt.Errorf(msg("logs.read_failed"), err)
+	}
+	if len(args) == 0 {
+		result := &logRunsResult{Runs: []logRunSummary{}}
+		for i := len(runs) - 1; i >= 0; i-- {
+			logs, _ := filepath.Glob(filepath.Join(LogsDir, runs[i], "*.log"))
+			result.Runs = append(result.Runs, logRunSummary{ID: runs[i], Logs: len(logs)})
+		}
+		return emitResult(result, func() {
+			if len(runs) == 0 {
+				fmt.Println(msg("logs.none"))
+				return
+			}
+			fmt.Println(msg("logs.runs"))
+			for _, run := range result.Runs {
+				fmt.Println(msg("logs.run_entry", run.ID, run.Logs))
+			}
+			fmt.Println()
+			fmt.Println(msg("logs.hint"))
+		})
+	}
+	if len(runs) == 0 {
+		return errors.New(msg("logs.none"))
+	}
+
+	for _, arg := range args {
+		if !isLogName(arg) {
+			return usageError(msg("logs.invalid_name"), arg)
+		}
+	}
+
+	runID := args[0]
+	if runID == "latest" {
+		runID = runs[len(runs)-1]
+	}
+	dir := filepath.Join(LogsDir, runID)
+	if !fileExists(dir) {
+		return fmt.Errorf(msg("logs.run_missing"), runID)
+	}
+
+	logs, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		return fmt.Errorf(msg("logs.read_failed"), err)
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		return modTime(logs[i]).Before(modTime(logs[j]))
+	})
+
+	// 指定了步骤时只输出该步骤的日志
+	if len(args) > 1 {
+		step := strings.TrimSuffix(args[1], ".log")
+		path := filepath.Join(dir, step+".log")
+		if !fileExists(path) {
+			return fmt.Errorf(msg("logs.step_missing"), runID, step)
+		}
+		logs = []string{path}
+	}
+
+	result := &logRunResult{Run: runID, Logs: []logFile{}}
+	for _, path := range logs {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Println(msg("logs.read_log_failed", err))
+			continue
+		}
+		if machineOutput() {
+			result.Logs = append(result.Logs, logFile{Path: filepath.ToSlash(path), Content: string(data)})
+			continue
+		}
+		fmt.Printf("==> %s <==\n", path)
+		os.Stdout.Write(data)
+		fmt.Println()
+	}
+	return emitResult(result, nil)
+}
+
+// isLogName 判断运行 ID 或步骤名是否只是一个文件名，拒绝路径分隔符和 ..，避免读取日志目录之外的文件
+func isLogName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `/\`) && !strings.Contains(name, "..")
+}
+
+// logRunsResult ghc logs 列出运行记录的结果
+type logRunsResult struct {
+	Runs []logRunSummary `json:"runs" yaml:"runs"`
+}
+
+// logRunSummary 一次运行及其日志数量
+type logRunSummary struct {
+	ID   string `json:"id" yaml:"id"`
+	Logs int    `json:"logs" yaml:"logs"`
+}
+
+// logRunResult ghc logs <run-id> 的结果
+type logRunResult struct {
+	Run  string    `json:"run" yaml:"run"`
+	Logs []logFile `json:"logs" yaml:"logs"`
+}
+
+// logFile 日志文件及其内容
+type logFile struct {
+	Path    string `json:"path" yaml:"path"`
+	Content string `json:"content" yaml:"content"`
+}
+
+// modTime 返回文件修改时间，读取失败时返回零值
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
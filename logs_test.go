@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleLogsRejectsMissingAndUnsafeNames(t *testing.T) {
+	t.Chdir(t.TempDir())
+	run := filepath.Join(LogsDir, "20250101-120000")
+	if err := os.MkdirAll(run, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(run, "build.log"), []byte("ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("secret.log", []byte("secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handleLogs([]string{"latest", "build"}); err != nil {
+		t.Errorf("ghc logs latest build: %v", err)
+	}
+	if err := handleLogs([]string{"latest", "biuld"}); err == nil {
+		t.Error("ghc logs with a missing step succeeded")
+	}
+
+	for _, args := range [][]string{
+		{"..", "secret"},
+		{"latest", "../../../secret"},
+		{"../logs/20250101-120000"},
+		{"latest", `..\..\..\secret`},
+	} {
+		if err := handleLogs(args); !errors.Is(err, ErrUsage) {
+			t.Errorf("ghc logs %q = %v, want a usage error", args, err)
+		}
+	}
+}
@@ -1,49 +1,182 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"flag"
 	"os"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		showHelp()
-		return
-	}
-
-	command := os.Args[1]
-	args := os.Args[2:]
-
-	switch command {
-	case "init":
-		handleInit()
-	case "bind":
-		handleBind(args)
-	case "status":
-		handleStatus()
-	case "tag":
-		handleTag(args)
-	case "publish", "release":
-		handlePublish(args)
-	case "help", "-h", "--help":
-		showHelp()
-	default:
-		fmt.Printf("未知命令: %s\n", command)
-		showHelp()
+	setupLocale()
+
+	ctx, stop := withInterrupt(context.Background())
+	err := rootCommand().execute(ctx, os.Args[1:])
+	stop()
+	if err != nil {
+		os.Exit(reportError(err))
 	}
 }
 
-func showHelp() {
-	fmt.Println("ghc - GitHub 配置管理工具")
-	fmt.Println("")
-	fmt.Println("使用方法:")
-	fmt.Println("  ghc init                    初始化项目配置")
-	fmt.Println("  ghc bind <repo-url>         绑定仓库地址")
-	fmt.Println("  ghc status                  查看当前状态")
-	fmt.Println("  ghc tag <version>           创建新标签")
-	fmt.Println("  ghc tag list                查看所有标签")
-	fmt.Println("  ghc tag checkout <version>  切换到指定版本")
-	fmt.Println("  ghc publish [version]       发布项目到 GitHub")
-	fmt.Println("  ghc release [version]       发布项目到 GitHub (同 publish)")
-	fmt.Println("  ghc help                    显示帮助信息")
+// rootCommand 构建命令树，修改仓库或配置的命令在运行前获取仓库锁，避免多个 ghc 同时运行
+func rootCommand() *cliCommand {
+	root := &cliCommand{Name: "ghc"}
+
+	var fixRemote bool
+	bind := &cliCommand{
+		Name: "bind", Args: "[repo-url]", Short: "help.cmd.bind", MaxArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&fixRemote, "fix-remote", false, msg("flag.fix_remote"))
+		},
+		Mutates: always,
+		Run: func(ctx context.Context, args []string) error {
+			repoURL := ""
+			if len(args) > 0 {
+				repoURL = args[0]
+			}
+			return handleBind(repoURL, fixRemote)
+		},
+	}
+
+	var statusJSON bool
+	status := &cliCommand{
+		Name: "status", Short: "help.cmd.status",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&statusJSON, "json", false, msg("flag.json"))
+		},
+		Run: func(ctx context.Context, args []string) error {
+			if statusJSON {
+				setOutputFormat(OutputJSON)
+			}
+			return handleStatus()
+		},
+	}
+
+	tag := &cliCommand{
+		Name: "tag", Args: "<version>", Short: "help.cmd.tag", MinArgs: 1, MaxArgs: 1,
+		Flags:   packageFlag,
+		Mutates: always,
+		Run: func(ctx context.Context, args []string) error {
+			return handleTagCreate(ctx, args[0])
+		},
+	}
+	tag.add(
+		&cliCommand{
+			Name: "list", Short: "help.cmd.tag_list",
+			Flags: packageFlag,
+			Run:   func(ctx context.Context, args []string) error { return handleTagList() },
+		},
+		&cliCommand{
+			Name: "checkout", Args: "<version>", Short: "help.cmd.tag_checkout", MinArgs: 1, MaxArgs: 1,
+			Mutates:  always,
+			Complete: completeTags,
+			Run: func(ctx context.Context, args []string) error {
+				return handleTagCheckout(args[0])
+			},
+		},
+	)
+
+	publish := &cliCommand{
+		Name: "publish", Aliases: []string{"release"}, Args: "[version]", Short: "help.cmd.publish", Long: "help.long.publish", MaxArgs: 1,
+		Flags:   packageFlag,
+		Mutates: always,
+		Run: func(ctx context.Context, args []string) error {
+			version := ""
+			if len(args) > 0 {
+				version = args[0]
+			}
+			return handlePublish(ctx, version)
+		},
+	}
+
+	bump := &cliCommand{
+		Name: "bump", Args: "[major|minor|patch]", Short: "help.cmd.bump", Long: "help.long.bump", MaxArgs: 1,
+		Flags:   packageFlag,
+		Mutates: always,
+		Complete: func(positional []string) []string {
+			if len(positional) > 0 {
+				return nil
+			}
+			return []string{"major", "minor", "patch"}
+		},
+		Run: func(ctx context.Context, args []string) error {
+			part := "patch"
+			if len(args) > 0 {
+				part = args[0]
+			}
+			return handleBump(part)
+		},
+	}
+
+	var changelogSince string
+	changelog := &cliCommand{
+		Name: "changelog", Short: "help.cmd.changelog",
+		Flags: func(fs *flag.FlagSet) {
+			packageFlag(fs)
+			fs.StringVar(&changelogSince, "since", "", msg("flag.changelog_since"))
+		},
+		Run: func(ctx context.Context, args []string) error { return handleChangelog(changelogSince) },
+	}
+
+	logs := &cliCommand{
+		Name: "logs", Args: "[run-id] [step]", Short: "help.cmd.logs", MaxArgs: 2,
+		Complete: completeLogs,
+		Run:      func(ctx context.Context, args []string) error { return handleLogs(args) },
+	}
+
+	var historyJSON bool
+	history := &cliCommand{
+		Name: "history", Short: "help.cmd.history",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&historyJSON, "json", false, msg("flag.json"))
+		},
+		Run: func(ctx context.Context, args []string) error {
+			if historyJSON {
+				setOutputFormat(OutputJSON)
+			}
+			return handleHistory()
+		},
+	}
+	history.add(&cliCommand{
+		Name: "verify", Short: "help.cmd.history_verify",
+		Run: func(ctx context.Context, args []string) error { return handleHistoryVerify() },
+	})
+
+	var force bool
+	unlock := &cliCommand{
+		Name: "unlock", Short: "help.cmd.unlock",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&force, "force", false, msg("flag.unlock_force"))
+		},
+		Run: func(ctx context.Context, args []string) error { return handleUnlock(force) },
+	}
+
+	lock := &cliCommand{Name: "lock", Short: "help.cmd.lock"}
+	lock.add(&cliCommand{
+		Name: "rebuild", Short: "help.cmd.lock_rebuild",
+		Mutates: always,
+		Run:     func(ctx context.Context, args []string) error { return handleLockRebuild() },
+	})
+
+	root.add(
+		&cliCommand{
+			Name: "init", Short: "help.cmd.init",
+			Mutates: always,
+			Run:     func(ctx context.Context, args []string) error { return handleInit() },
+		},
+		bind,
+		status,
+		tag,
+		bump,
+		changelog,
+		publish,
+		logs,
+		history,
+		configCommand(),
+		unlock,
+		lock,
+		completionCommand(root),
+		helpCommand(root),
+		completeCommand(root),
+	)
+	return root
 }
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentSchemaVersion 当前配置文件的版本，没有 schema_version 的配置视为版本 1
+const CurrentSchemaVersion = 2
+
+// configMigration 把配置从 From 版本升级到 From+1 版本
+type configMigration struct {
+	From        int
+	Description string // 说明在 messages 中的键
+	Apply       func(root *yaml.Node) error
+}
+
+// configMigrations 按版本排列的迁移，新增配置版本时在末尾追加并增加 CurrentSchemaVersion
+var configMigrations = []configMigration{
+	{From: 1, Description: "migrate.v1_prebuild_steps", Apply: migratePreBuildSteps},
+}
+
+// configSchemaVersion 读取配置的版本
+func configSchemaVersion(root *yaml.Node) (int, error) {
+	node, _ := mappingValue(root, "schema_version")
+	if node == nil {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(node.Value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf(msg("migrate.invalid_version"), node.Value)
+	}
+	return version, nil
+}
+
+// setSchemaVersion 设置配置的版本，新增时放在文件开头
+func setSchemaVersion(root *yaml.Node, version int) {
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+	if node, _ := mappingValue(root, "schema_version"); node != nil {
+		replaceNodeValue(node, value)
+		return
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "schema_version"}
+	// 文件开头的注释仍然留在文件开头
+	if len(root.Content) > 0 {
+		key.HeadComment = root.Content[0].HeadComment
+		root.Content[0].HeadComment = ""
+	}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
+
+// migrateConfigDocument 依次执行迁移，把配置升级到当前版本，返回升级前的版本和执行的迁移
+func migrateConfigDocument(doc *yaml.Node) (int, []configMigration, error) {
+	if doc.Kind == 0 || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return CurrentSchemaVersion, nil, nil
+	}
+	root := doc.Content[0]
+
+	from, err := configSchemaVersion(root)
+	if err != nil {
+		return 0, nil, err
+	}
+	if from > CurrentSchemaVersion {
+		return from, nil, fmt.Errorf(msg("migrate.too_new"), from, CurrentSchemaVersion)
+	}
+
+	var applied []configMigration
+	for _, migration := range configMigrations {
+		if migration.From < from {
+			continue
+		}
+		if err := migration.Apply(root); err != nil {
+			return from, applied, fmt.Errorf(msg("migrate.step_failed"), migration.From, err)
+		}
+		applied = append(applied, migration)
+	}
+	if len(applied) > 0 {
+		setSchemaVersion(root, CurrentSchemaVersion)
+	}
+	return from, applied, nil
+}
+
+// migrateConfigFile 升级配置文件，有迁移时先备份原文件再写回，返回升级的内容，不需要升级时返回 nil
+func migrateConfigFile(path string, doc *yaml.Node) (*configMigrateFile, error) {
+	from, applied, err := migrateConfigDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	file := newConfigMigrateFile(path, from, applied)
+	file.Backup = path + ".bak"
+	original, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(msg("config.read_failed"), err)
+	}
+	if err := writeFileAtomic(file.Backup, original, 0644); err != nil {
+		return nil, fmt.Errorf(msg("migrate.backup_failed"), err)
+	}
+	if err := saveConfigDocument(path, doc); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// newConfigMigrateFile 记录一个配置文件的升级内容
+func newConfigMigrateFile(path string, from int, applied []configMigration) *configMigrateFile {
+	file := &configMigrateFile{Path: path, From: from, Migrations: []string{}}
+	for _, migration := range applied {
+		file.Migrations = append(file.Migrations, msg(migration.Description))
+	}
+	return file
+}
+
+// printConfigMigration 输出已经升级的配置文件和执行的迁移
+func printConfigMigration(file *configMigrateFile) {
+	fmt.Println(msg("migrate.migrated", file.Path, file.From, CurrentSchemaVersion, file.Backup))
+	printMigrations(file)
+}
+
+// printMigrations 逐行输出迁移说明
+func printMigrations(file *configMigrateFile) {
+	for _, migration := range file.Migrations {
+		fmt.Printf("  - %s\n", migration)
+	}
+}
+
+// migratePreBuildSteps 版本 1 到 2：把 script 和 commands 转换为依次依赖的 steps，
+// 步骤 ID 与 buildPreBuildGraph 的转换结果一致，已有步骤中的 needs 仍然有效；
+// profiles 中的预编译配置同样转换
+func migratePreBuildSteps(root *yaml.Node) error {
+	targets := []*yaml.Node{root}
+	if profiles, _ := mappingValue(root, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+		for i := 1; i < len(profiles.Content); i += 2 {
+			if profiles.Content[i].Kind == yaml.MappingNode {
+				targets = append(targets, profiles.Content[i])
+			}
+		}
+	}
+
+	for _, target := range targets {
+		preBuild, _ := mappingValue(target, "pre_build")
+		if preBuild == nil || preBuild.Kind != yaml.MappingNode {
+			continue
+		}
+
+		var steps []*yaml.Node
+		previous := ""
+		chain := func(id string, run *yaml.Node) {
+			step := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: run.HeadComment, LineComment: run.LineComment}
+			step.Content = append(step.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "id"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: id},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "run"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: run.Value, Style: run.Style})
+			if previous != "" {
+				step.Content = append(step.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "needs"},
+					&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{
+						{Kind: yaml.ScalarNode, Tag: "!!str", Value: previous},
+					}})
+			}
+			steps = append(steps, step)
+			previous = id
+		}
+
+		if script, _ := mappingValue(preBuild, "script"); script != nil && script.Kind == yaml.ScalarNode && script.Value != "" {
+			chain("script", script)
+		}
+		if commands, _ := mappingValue(preBuild, "commands"); commands != nil && commands.Kind == yaml.SequenceNode {
+			for i, command := range commands.Content {
+				if command.Kind == yaml.ScalarNode && command.Value != "" {
+					chain(fmt.Sprintf("cmd-%d", i+1), command)
+				}
+			}
+		}
+
+		removeMappingKey(preBuild, "script")
+		removeMappingKey(preBuild, "commands")
+		if len(steps) == 0 {
+			continue
+		}
+
+		if existing, _ := mappingValue(preBuild, "steps"); existing != nil && existing.Kind == yaml.SequenceNode {
+			existing.Content = append(steps, existing.Content...)
+			continue
+		}
+		preBuild.Content = append(preBuild.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "steps"},
+			&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: steps})
+	}
+	return nil
+}
+
+// removeMappingKey 删除映射节点中的键
+func removeMappingKey(mapping *yaml.Node, key string) {
+	if _, i := mappingValue(mapping, key); i >= 0 {
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// v1Config 没有 schema_version 的版本 1 配置，预编译使用 script 和 commands
+const v1Config = `# 项目配置
+repo: "https://github.com/owner/repo.git"
+branch: main
+version: 1.0.0
+pre_build:
+  enabled: true
+  script: ./gen.sh # 生成代码
+  commands:
+    - go vet ./...
+    - go test ./...
+profiles:
+  ci:
+    pre_build:
+      enabled: true
+      commands:
+        - make lint
+`
+
+// parseConfigYAML 把配置文本解析为节点树
+func parseConfigYAML(t *testing.T, content string) *yaml.Node {
+	t.Helper()
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return &doc
+}
+
+func TestMigrateConfigDocumentFromV1(t *testing.T) {
+	doc := parseConfigYAML(t, v1Config)
+	from, applied, err := migrateConfigDocument(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != 1 || len(applied) != 1 || applied[0].From != 1 {
+		t.Fatalf("migrateConfigDocument = from %d, %d migrations", from, len(applied))
+	}
+
+	var config Config
+	if err := doc.Decode(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", config.SchemaVersion, CurrentSchemaVersion)
+	}
+	// script 和 commands 依次转换为首尾相连的步骤
+	want := []PreBuildStep{
+		{ID: "script", Run: "./gen.sh"},
+		{ID: "cmd-1", Run: "go vet ./...", Needs: []string{"script"}},
+		{ID: "cmd-2", Run: "go test ./...", Needs: []string{"cmd-1"}},
+	}
+	if got := config.PreBuild.Steps; !sameSteps(got, want) {
+		t.Errorf("pre_build.steps = %+v, want %+v", got, want)
+	}
+	if got := config.Profiles["ci"].PreBuild.Steps; !sameSteps(got, []PreBuildStep{{ID: "cmd-1", Run: "make lint"}}) {
+		t.Errorf("profiles.ci.pre_build.steps = %+v", got)
+	}
+
+	// 已是当前版本的配置不再升级
+	from, applied, err = migrateConfigDocument(doc)
+	if err != nil || from != CurrentSchemaVersion || len(applied) != 0 {
+		t.Errorf("second migrateConfigDocument = from %d, %d migrations, %v", from, len(applied), err)
+	}
+}
+
+// sameSteps 比较步骤的 ID、命令和依赖
+func sameSteps(got, want []PreBuildStep) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i].ID != want[i].ID || got[i].Run != want[i].Run || strings.Join(got[i].Needs, ",") != strings.Join(want[i].Needs, ",") {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMigrateConfigDocumentRejectsBadVersions(t *testing.T) {
+	for _, content := range []string{
+		"schema_version: 99\n",
+		"schema_version: 0\n",
+		"schema_version: two\n",
+	} {
+		if _, _, err := migrateConfigDocument(parseConfigYAML(t, content)); err == nil {
+			t.Errorf("migrateConfigDocument(%q) succeeded, want an error", content)
+		}
+	}
+}
+
+func TestHandleConfigMigrate(t *testing.T) {
+	setupConfigDir(t, v1Config)
+
+	// --check 只报告需要升级的文件，不修改文件
+	if err := handleConfigMigrate(true); err == nil {
+		t.Error("config migrate --check on a v1 config succeeded")
+	}
+	if data, _ := os.ReadFile(ConfigFile); string(data) != v1Config {
+		t.Errorf("config migrate --check modified the file:\n%s", data)
+	}
+
+	if err := handleConfigMigrate(false); err != nil {
+		t.Fatalf("config migrate: %v", err)
+	}
+	if data, _ := os.ReadFile(ConfigFile + ".bak"); string(data) != v1Config {
+		t.Errorf("backup = %q, want the original config", data)
+	}
+	data, _ := os.ReadFile(ConfigFile)
+	if !strings.HasPrefix(string(data), "# 项目配置\nschema_version: 2\n") || strings.Contains(string(data), "commands:") {
+		t.Errorf("migrated config:\n%s", data)
+	}
+
+	if err := handleConfigMigrate(true); err != nil {
+		t.Errorf("config migrate --check after migrating: %v", err)
+	}
+}
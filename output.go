@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 输出格式，通过全局参数 --output 选择
+const (
+	OutputText = "text" // 面向人的文本（默认）
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// outputFormat 当前的输出格式
+var outputFormat = OutputText
+
+// resultOutput 命令结果的输出位置。使用 json 或 yaml 时进度信息和子进程输出
+// 都改为写入 stderr，stdout 只包含结果对象，便于脚本解析
+var resultOutput io.Writer = os.Stdout
+
+// errorOutput 结构化错误的输出位置
+var errorOutput io.Writer = os.Stderr
+
+// 退出码，数值保持稳定，脚本可以依赖
+const (
+	ExitOK             = 0 // 成功
+	ExitFailure        = 1 // 其他错误
+	ExitUsage          = 2 // 命令或参数错误
+	ExitPartialFailure = 3 // 只有部分远程仓库或托管平台操作成功
+	ExitConfig         = 4 // 配置文件缺失、无效或无法保存
+	ExitGit            = 5 // git 仓库或标签操作失败
+	ExitBuild          = 6 // 编译或预编译步骤失败
+	ExitNetwork        = 7 // 推送或托管平台 API 请求失败
+	ExitLockBusy       = 8 // 另一个 ghc 进程持有仓库锁
+)
+
+// 错误码，与退出码一一对应，随结构化错误一起输出
+const (
+	ErrCodeFailure  = "error"
+	ErrCodeUsage    = "usage"
+	ErrCodePartial  = "partial"
+	ErrCodeConfig   = "config"
+	ErrCodeGit      = "git"
+	ErrCodeBuild    = "build"
+	ErrCodeNetwork  = "network"
+	ErrCodeLockBusy = "lock_busy"
+)
+
+// errorExitCodes 错误码对应的退出码
+var errorExitCodes = map[string]int{
+	ErrCodeFailure:  ExitFailure,
+	ErrCodeUsage:    ExitUsage,
+	ErrCodePartial:  ExitPartialFailure,
+	ErrCodeConfig:   ExitConfig,
+	ErrCodeGit:      ExitGit,
+	ErrCodeBuild:    ExitBuild,
+	ErrCodeNetwork:  ExitNetwork,
+	ErrCodeLockBusy: ExitLockBusy,
+}
+
+// errorResult 结构化错误，--output json|yaml 时写入 stderr
+type errorResult struct {
+	Error struct {
+		Code     string `json:"code" yaml:"code"`
+		ExitCode int    `json:"exit_code" yaml:"exit_code"`
+		Message  string `json:"message" yaml:"message"`
+	} `json:"error" yaml:"error"`
+}
+
+// setOutputFormat 设置输出格式，json 和 yaml 把 stdout 留给结果对象
+func setOutputFormat(format string) error {
+	switch format {
+	case OutputText:
+	case OutputJSON, OutputYAML:
+		if outputFormat == OutputText {
+			resultOutput = os.Stdout
+			os.Stdout = os.Stderr
+		}
+	default:
+		return fmt.Errorf(msg("output.invalid"), format)
+	}
+	outputFormat = format
+	return nil
+}
+
+// machineOutput 是否输出 json 或 yaml
+func machineOutput() bool {
+	return outputFormat != OutputText
+}
+
+// emitResult 输出命令结果：text 格式调用 text 输出文本，json 和 yaml 格式把 result 写入 stdout
+func emitResult(result interface{}, text func()) error {
+	if !machineOutput() {
+		if text != nil {
+			text()
+		}
+		return nil
+	}
+	if err := encodeResult(resultOutput, result); err != nil {
+		return fmt.Errorf(msg("output.encode_failed"), err)
+	}
+	return nil
+}
+
+// encodeResult 按当前格式序列化结果
+func encodeResult(w io.Writer, v interface{}) error {
+	if outputFormat == OutputYAML {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// reportError 报告命令失败并返回对应的退出码：text 格式直接输出信息，
+// json 格式把错误码和信息作为单独一行写入 stderr，yaml 格式写入一个 YAML 文档
+func reportError(err error) int {
+	code := errorCode(err)
+	exit, ok := errorExitCodes[code]
+	if !ok {
+		code, exit = ErrCodeFailure, ExitFailure
+	}
+
+	if !machineOutput() {
+		fmt.Println(err)
+		return exit
+	}
+
+	var result errorResult
+	result.Error.Code = code
+	result.Error.ExitCode = exit
+	result.Error.Message = err.Error()
+	if outputFormat == OutputJSON {
+		data, _ := json.Marshal(&result)
+		fmt.Fprintln(errorOutput, string(data))
+	} else {
+		fmt.Fprintln(errorOutput, "---")
+		encodeResult(errorOutput, &result)
+	}
+	return exit
+}
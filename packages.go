@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// packageFlag 为支持 monorepo 的命令注册 --package
+func packageFlag(fs *flag.FlagSet) {
+	fs.StringVar(&activePackage, "package", activePackage, msg("flag.package"))
+}
+
+// packageTagPrefix 包的默认标签前缀，例如 services/api 对应 services/api/v
+func packageTagPrefix(dir string) string {
+	dir = strings.Trim(path.Clean(filepath.ToSlash(dir)), "/")
+	if dir == "" || dir == "." {
+		return "v"
+	}
+	return dir + "/v"
+}
+
+// packageDir 返回选中包的目录，未选中包时返回空字符串，表示整个项目
+func packageDir(config *Config) string {
+	if activePackage == "" {
+		return ""
+	}
+	return config.Packages[activePackage].Path
+}
+
+// configuredTagName 按配置中的 tag_prefix 为版本号加上前缀，与 ghc publish 创建的标签一致，
+// 选中包时使用包的标签前缀，例如 1.2.0 对应 api/v1.2.0；没有配置文件时原样返回，配置为 nil
+func configuredTagName(version string) (string, *Config, error) {
+	if activePackage == "" && findProjectConfig() == "" {
+		return version, nil, nil
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		return "", nil, configError(msg("common.load_config_failed"), err)
+	}
+	return releaseTagName(config, version), config, nil
+}
+
+// selectedTagPrefix 选中包时返回包的标签前缀，用于只列出该包的标签；未选中包时返回空字符串
+func selectedTagPrefix() (string, error) {
+	if activePackage == "" {
+		return "", nil
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		return "", configError(msg("common.load_config_failed"), err)
+	}
+	return config.TagPrefix, nil
+}
+
+// warnUnchangedPackage 自包的上一个标签以来没有修改包目录的提交时输出警告
+func warnUnchangedPackage(config *Config) {
+	gitOps, err := NewGitOperations(projectDir)
+	if err != nil {
+		return
+	}
+	if tag, since, err := gitOps.DescribeHead(config.TagPrefix, packageDir(config)); err == nil && since == 0 {
+		fmt.Println(msg("package.unchanged", activePackage, tag))
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestConfiguredTagName(t *testing.T) {
+	setupConfigDir(t, fourSpaceConfig)
+
+	for version, want := range map[string]string{"1.0.0": "v1.0.0", "v1.0.0": "v1.0.0"} {
+		tag, config, err := configuredTagName(version)
+		if err != nil {
+			t.Fatalf("configuredTagName(%q): %v", version, err)
+		}
+		if config == nil || tag != want {
+			t.Errorf("configuredTagName(%q) = %q, want %q", version, tag, want)
+		}
+	}
+}
+
+func TestConfiguredTagNameWithoutConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	tag, config, err := configuredTagName("1.0.0")
+	if err != nil || config != nil || tag != "1.0.0" {
+		t.Errorf("configuredTagName without config = %q, %v, %v; want 1.0.0 unchanged", tag, config, err)
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// stepStatus 预编译步骤的执行状态
+type stepStatus int
+
+const (
+	stepPending stepStatus = iota
+	stepSucceeded
+	stepFailed
+	stepIgnored
+	stepCanceled
+	stepSkipped
+)
+
+// String 返回状态的显示文本
+func (s stepStatus) String() string {
+	switch s {
+	case stepSucceeded:
+		return msg("prebuild.status_succeeded")
+	case stepFailed:
+		return msg("prebuild.status_failed")
+	case stepIgnored:
+		return msg("prebuild.status_ignored")
+	case stepCanceled:
+		return msg("prebuild.status_canceled")
+	case stepSkipped:
+		return msg("prebuild.status_skipped")
+	default:
+		return msg("prebuild.status_pending")
+	}
+}
+
+// preBuildNode 依赖图中的一个步骤节点
+type preBuildNode struct {
+	PreBuildStep
+	dependents []*preBuildNode
+	pending    int // 尚未完成的依赖数量
+	status     stepStatus
+	duration   time.Duration
+	err        error
+	log        *commandLog
+}
+
+// buildPreBuildGraph 根据预编译配置构建步骤依赖图
+// script 与 commands 会被转换为依次依赖的步骤（ID 分别为 script 和 cmd-N），
+// 以保持原有的顺序执行语义；steps 中的步骤可以通过 needs 引用它们
+func buildPreBuildGraph(cfg PreBuildConfig) ([]*preBuildNode, error) {
+	var steps []PreBuildStep
+	previous := ""
+	chain := func(id, run string) {
+		step := PreBuildStep{ID: id, Run: run}
+		if previous != "" {
+			step.Needs = []string{previous}
+		}
+		steps = append(steps, step)
+		previous = id
+	}
+
+	if cfg.Script != "" {
+		chain("script", cfg.Script)
+	}
+	for i, command := range cfg.Commands {
+		if command == "" {
+			continue
+		}
+		chain(fmt.Sprintf("cmd-%d", i+1), command)
+	}
+	steps = append(steps, cfg.Steps...)
+
+	nodes := make([]*preBuildNode, 0, len(steps))
+	byID := make(map[string]*preBuildNode, len(steps))
+	for i, step := range steps {
+		if step.ID == "" {
+			return nil, fmt.Errorf(msg("prebuild.missing_id"), i+1)
+		}
+		if strings.TrimSpace(step.Run) == "" {
+			return nil, fmt.Errorf(msg("prebuild.missing_run"), step.ID)
+		}
+		if _, exists := byID[step.ID]; exists {
+			return nil, fmt.Errorf(msg("prebuild.duplicate_id"), step.ID)
+		}
+		node := &preBuildNode{PreBuildStep: step}
+		byID[step.ID] = node
+		nodes = append(nodes, node)
+	}
+
+	for _, node := range nodes {
+		for _, need := range node.Needs {
+			dep, ok := byID[need]
+			if !ok {
+				return nil, fmt.Errorf(msg("prebuild.unknown_need"), node.ID, need)
+			}
+			if dep == node {
+				return nil, fmt.Errorf(msg("prebuild.self_need"), node.ID)
+			}
+			dep.dependents = append(dep.dependents, node)
+			node.pending++
+		}
+	}
+
+	if cycle := findPreBuildCycle(nodes); cycle != nil {
+		return nil, fmt.Errorf(msg("prebuild.cycle"), strings.Join(cycle, " -> "))
+	}
+
+	return nodes, nil
+}
+
+// findPreBuildCycle 查找依赖图中的环，返回环上的步骤 ID，无环时返回 nil
+func findPreBuildCycle(nodes []*preBuildNode) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*preBuildNode]int, len(nodes))
+	var path []string
+	var visit func(node *preBuildNode) []string
+	visit = func(node *preBuildNode) []string {
+		state[node] = visiting
+		path = append(path, node.ID)
+		for _, next := range node.dependents {
+			switch state[next] {
+			case visiting:
+				for i, id := range path {
+					if id == next.ID {
+						return append(append([]string{}, path[i:]...), next.ID)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+		return nil
+	}
+
+	for _, node := range nodes {
+		if state[node] == unvisited {
+			if cycle := visit(node); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// runPreBuildGraph 按依赖关系并发执行预编译步骤
+// 某个步骤失败且 failOnError 为 true 时，通过 context 取消其余正在执行的步骤
+func runPreBuildGraph(ctx context.Context, nodes []*preBuildNode, cfg PreBuildConfig) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	var ready []*preBuildNode
+	for _, node := range nodes {
+		if node.pending == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	var outputMu sync.Mutex
+	done := make(chan *preBuildNode)
+	running := 0
+	var firstErr error
+
+	for {
+		for ctx.Err() == nil && len(ready) > 0 && running < concurrency {
+			node := ready[0]
+			ready = ready[1:]
+			running++
+			go func(node *preBuildNode) {
+				timeout := node.Timeout
+				if timeout <= 0 {
+					timeout = cfg.Timeout
+				}
+				prefixed := newPrefixWriter(os.Stdout, fmt.Sprintf("[%s] ", node.ID), &outputMu)
+				log := openCommandLog("prebuild-"+node.ID, node.Run)
+				out := log.Tee(prefixed)
+				start := time.Now()
+				node.err = runCommandWithTimeout(ctx, node.Run, timeout, out, out)
+				node.duration = time.Since(start)
+				prefixed.Flush()
+				node.log = log
+				done <- node
+			}(node)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		node := <-done
+		running--
+		switch {
+		case node.err == nil:
+			node.status = stepSucceeded
+		case firstErr != nil || (ctx.Err() != nil && cfg.FailOnError):
+			node.status = stepCanceled
+		case cfg.FailOnError:
+			node.status = stepFailed
+			firstErr = fmt.Errorf(msg("prebuild.step_failed"), node.ID, node.err)
+			cancel()
+		default:
+			node.status = stepIgnored
+		}
+
+		// 被取消的步骤不输出日志末尾，避免淹没真正的失败原因
+		outputMu.Lock()
+		if node.status == stepCanceled {
+			node.log.Finish(nil)
+		} else {
+			node.log.Finish(node.err)
+		}
+		outputMu.Unlock()
+
+		for _, dependent := range node.dependents {
+			dependent.pending--
+			if dependent.pending == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		if node.status == stepPending {
+			node.status = stepSkipped
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf(msg("prebuild.canceled"), context.Cause(ctx))
+	}
+	return nil
+}
+
+// printPreBuildSummary 输出预编译步骤的耗时汇总
+func printPreBuildSummary(nodes []*preBuildNode, total time.Duration, concurrency int) {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	fmt.Println(msg("prebuild.summary"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, node := range nodes {
+		duration := "-"
+		if node.status != stepSkipped {
+			duration = node.duration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", node.ID, node.status, duration)
+	}
+	w.Flush()
+	fmt.Println(msg("prebuild.total", total.Round(time.Millisecond), concurrency))
+}
+
+// prefixWriter 为每一行输出添加步骤前缀，多个步骤共享同一把锁避免输出交错
+type prefixWriter struct {
+	out    io.Writer
+	prefix string
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+// newPrefixWriter 创建带前缀的输出写入器
+func newPrefixWriter(out io.Writer, prefix string, mu *sync.Mutex) *prefixWriter {
+	return &prefixWriter{out: out, prefix: prefix, mu: mu}
+}
+
+// Write 缓冲输出并按完整行写出
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadBytes('\n')
+		if err != nil {
+			// 不完整的行放回缓冲区，等待后续输出
+			w.buf.Write(line)
+			break
+		}
+		if _, err := fmt.Fprintf(w.out, "%s%s", w.prefix, line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush 写出缓冲区中剩余的不完整行
+func (w *prefixWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		fmt.Fprintf(w.out, "%s%s\n", w.prefix, w.buf.String())
+		w.buf.Reset()
+	}
+}
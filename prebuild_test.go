@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupPreBuildDir 切换到临时目录并写入 record.sh，执行 sh record.sh <id> 会把 id 追加到 order.txt
+func setupPreBuildDir(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("record.sh", []byte("echo $1 >> order.txt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stepStatuses 按 ID 返回每个步骤的状态
+func stepStatuses(nodes []*preBuildNode) map[string]stepStatus {
+	statuses := make(map[string]stepStatus, len(nodes))
+	for _, node := range nodes {
+		statuses[node.ID] = node.status
+	}
+	return statuses
+}
+
+func TestRunPreBuildGraphFollowsNeeds(t *testing.T) {
+	setupPreBuildDir(t)
+	cfg := PreBuildConfig{
+		Enabled:     true,
+		FailOnError: true,
+		Concurrency: 4,
+		Steps: []PreBuildStep{
+			{ID: "package", Run: "sh record.sh package", Needs: []string{"vet", "test"}},
+			{ID: "vet", Run: "sh record.sh vet", Needs: []string{"generate"}},
+			{ID: "test", Run: "sh record.sh test", Needs: []string{"generate"}},
+			{ID: "generate", Run: "sh record.sh generate"},
+		},
+	}
+	nodes, err := buildPreBuildGraph(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runPreBuildGraph(context.Background(), nodes, cfg); err != nil {
+		t.Fatalf("runPreBuildGraph: %v", err)
+	}
+
+	data, err := os.ReadFile("order.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := strings.Fields(string(data))
+	if len(order) != 4 || order[0] != "generate" || order[3] != "package" {
+		t.Errorf("steps ran in order %v, want generate first and package last", order)
+	}
+	for id, status := range stepStatuses(nodes) {
+		if status != stepSucceeded {
+			t.Errorf("step %s status = %v, want succeeded", id, status)
+		}
+	}
+}
+
+func TestBuildPreBuildGraphRejectsBadNeeds(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []PreBuildStep
+		want  string
+	}{
+		{"cycle", []PreBuildStep{
+			{ID: "a", Run: "true", Needs: []string{"c"}},
+			{ID: "b", Run: "true", Needs: []string{"a"}},
+			{ID: "c", Run: "true", Needs: []string{"b"}},
+		}, "a -> b -> c -> a"},
+		{"self", []PreBuildStep{{ID: "a", Run: "true", Needs: []string{"a"}}}, "a"},
+		{"unknown", []PreBuildStep{{ID: "a", Run: "true", Needs: []string{"missing"}}}, "missing"},
+		{"duplicate", []PreBuildStep{{ID: "a", Run: "true"}, {ID: "a", Run: "true"}}, "a"},
+	}
+	for _, tc := range tests {
+		_, err := buildPreBuildGraph(PreBuildConfig{Steps: tc.steps})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: buildPreBuildGraph error = %v, want one mentioning %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestRunPreBuildGraphCancelsSiblingsOnFailure(t *testing.T) {
+	setupPreBuildDir(t)
+	cfg := PreBuildConfig{
+		Enabled:     true,
+		FailOnError: true,
+		Concurrency: 2,
+		Steps: []PreBuildStep{
+			{ID: "slow", Run: "sleep 30"},
+			{ID: "broken", Run: "sh -c false"},
+			{ID: "after", Run: "sh record.sh after", Needs: []string{"slow"}},
+		},
+	}
+	nodes, err := buildPreBuildGraph(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err = runPreBuildGraph(context.Background(), nodes, cfg)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("runPreBuildGraph error = %v, want the broken step", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("runPreBuildGraph took %v, the slow step was not canceled", elapsed)
+	}
+
+	want := map[string]stepStatus{"slow": stepCanceled, "broken": stepFailed, "after": stepSkipped}
+	for id, status := range stepStatuses(nodes) {
+		if status != want[id] {
+			t.Errorf("step %s status = %v, want %v", id, status, want[id])
+		}
+	}
+	if _, err := os.Stat("order.txt"); err == nil {
+		t.Error("a step that needs the canceled step still ran")
+	}
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup 让命令在独立的进程组中运行，便于统一终止其子进程
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// signalProcessGroup Windows 不支持向进程组发送信号，直接终止整个进程树
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return killProcessGroup(cmd)
+}
+
+// signalProcess Windows 不支持向进程发送 SIGINT 或 SIGTERM，直接终止命令
+func signalProcess(cmd *exec.Cmd, sig os.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// killProcessGroup 通过 taskkill 终止命令及其所有子进程
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// forgeTokenEnv 各托管平台读取 API 令牌的环境变量
+var forgeTokenEnv = map[string][]string{
+	ForgeGitHub: {"GITHUB_TOKEN", "GH_TOKEN"},
+	ForgeGitLab: {"GITLAB_TOKEN", "CI_JOB_TOKEN"},
+	ForgeGitea:  {"GITEA_TOKEN"},
+}
+
+// resolveForgeToken 确定托管平台 API 令牌
+// 优先使用配置中的 token，其次是 GHC_TOKEN 和平台对应的环境变量
+func resolveForgeToken(config *Config, forge string) string {
+	if config.Token != "" {
+		return config.Token
+	}
+	for _, name := range append([]string{"GHC_TOKEN"}, forgeTokenEnv[forge]...) {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// releaseProviderFor 根据绑定的主仓库地址或 forge 配置选择托管平台的发布接口
+func releaseProviderFor(config *Config) (ReleaseProvider, error) {
+	primary, ok := config.PrimaryRemote()
+	if !ok {
+		return nil, errors.New(msg("release.not_bound"))
+	}
+
+	repo, err := ParseRepoURL(primary.URL)
+	if err != nil {
+		return nil, err
+	}
+	forge, err := DetectForge(config, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	token := resolveForgeToken(config, forge)
+	if token == "" {
+		fmt.Println(msg("release.no_token", forge))
+	}
+	return NewReleaseProvider(forge, repo, config.Release.APIURL, token)
+}
+
+// resolveReleaseAssets 展开附件通配符，没有匹配任何文件的模式视为错误
+func resolveReleaseAssets(patterns []string) ([]string, error) {
+	var assets []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(msg("release.invalid_pattern"), pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf(msg("release.no_match"), pattern)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() && !seen[match] {
+				seen[match] = true
+				assets = append(assets, match)
+			}
+		}
+	}
+	return assets, nil
+}
+
+// publishRelease 在托管平台上为已推送的标签创建发布并上传附件
+func publishRelease(ctx context.Context, config *Config, version string) (*ReleaseInfo, error) {
+	// 使用 --dry-run 时构建已被跳过，附件可能不存在
+	if dryRunSkip(msg("dryrun.release", version)) {
+		return &ReleaseInfo{}, nil
+	}
+
+	// 先检查附件，避免创建发布后才发现附件缺失
+	assets, err := resolveReleaseAssets(config.Release.Assets)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := releaseProviderFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	name := config.Release.Name
+	if name == "" {
+		name = version
+	}
+	release := Release{
+		TagName:    releaseTagName(config, version),
+		Name:       strings.ReplaceAll(name, "{version}", version),
+		Body:       strings.ReplaceAll(config.Release.Notes, "{version}", version),
+		Draft:      config.Release.Draft,
+		Prerelease: config.Release.Prerelease,
+	}
+
+	// 上次发布在上传附件时失败，重新发布时沿用已创建的发布
+	info, err := provider.GetRelease(ctx, release.TagName)
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		fmt.Println(msg("release.exists", provider.Name(), release.TagName))
+	} else {
+		fmt.Println(msg("release.creating", provider.Name(), release.Name))
+		info, err = provider.CreateRelease(ctx, release)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, asset := range assets {
+		fmt.Println(msg("release.uploading", asset))
+		if err := provider.UploadAsset(ctx, info, asset); err != nil {
+			return info, err
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// giteaReleaseConfig 发布到替身上的 Gitea 仓库的配置
+func giteaReleaseConfig(fake *fakeForge, asset string) *Config {
+	return &Config{
+		Repo:      "https://gitea.example.com/owner/repo.git",
+		TagPrefix: "v",
+		Token:     "secret",
+		Release: ReleaseConfig{
+			Enabled: true,
+			Name:    "repo {version}",
+			Assets:  []string{asset},
+			APIURL:  fake.URL + "/api/v1",
+		},
+	}
+}
+
+func TestPublishReleaseCreatesRelease(t *testing.T) {
+	fake := newFakeForge(t, map[string]fakeResponse{
+		"POST /api/v1/repos/owner/repo/releases":          {Status: http.StatusCreated, Body: `{"id":7,"tag_name":"v1.2.0"}`},
+		"POST /api/v1/repos/owner/repo/releases/7/assets": {Status: http.StatusCreated, Body: `{"id":1}`},
+	})
+	asset := writeAsset(t, "app.tar.gz", "binary data")
+
+	info, err := publishRelease(context.Background(), giteaReleaseConfig(fake, asset), "1.2.0")
+	if err != nil {
+		t.Fatalf("publishRelease: %v", err)
+	}
+	if info.ID != "7" {
+		t.Errorf("release ID = %q, want 7", info.ID)
+	}
+
+	var got []string
+	for _, req := range fake.recorded() {
+		got = append(got, req.Method+" "+req.Path)
+	}
+	want := []string{
+		"GET /api/v1/repos/owner/repo/releases/tags/v1.2.0",
+		"POST /api/v1/repos/owner/repo/releases",
+		"POST /api/v1/repos/owner/repo/releases/7/assets",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("requests = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+	if body := decodeJSONBody(t, fake.recorded()[1]); body["name"] != "repo 1.2.0" || body["tag_name"] != "v1.2.0" {
+		t.Errorf("create body = %v", body)
+	}
+}
+
+func TestPublishReleaseReusesExistingRelease(t *testing.T) {
+	fake := newFakeForge(t, map[string]fakeResponse{
+		"GET /api/v1/repos/owner/repo/releases/tags/v1.2.0": {Status: http.StatusOK, Body: `{"id":7,"tag_name":"v1.2.0","html_url":"https://gitea.example.com/owner/repo/releases/tag/v1.2.0"}`},
+		"POST /api/v1/repos/owner/repo/releases/7/assets":   {Status: http.StatusCreated, Body: `{"id":1}`},
+	})
+	asset := writeAsset(t, "app.tar.gz", "binary data")
+
+	info, err := publishRelease(context.Background(), giteaReleaseConfig(fake, asset), "1.2.0")
+	if err != nil {
+		t.Fatalf("publishRelease: %v", err)
+	}
+	if info.URL != "https://gitea.example.com/owner/repo/releases/tag/v1.2.0" {
+		t.Errorf("release URL = %q", info.URL)
+	}
+
+	// 已存在的发布不再创建，附件上传到该发布
+	for _, req := range fake.recorded() {
+		if req.Method == http.MethodPost && req.Path == "/api/v1/repos/owner/repo/releases" {
+			t.Error("publishRelease created a release although one exists")
+		}
+	}
+	requests := fake.recorded()
+	last := requests[len(requests)-1]
+	if last.Method != http.MethodPost || last.Path != "/api/v1/repos/owner/repo/releases/7/assets" {
+		t.Errorf("last request = %s %s, want the asset upload", last.Method, last.Path)
+	}
+}
+
+func TestPublishReleaseMissingAsset(t *testing.T) {
+	fake := newFakeForge(t, nil)
+	config := giteaReleaseConfig(fake, t.TempDir()+"/missing-*.tar.gz")
+
+	if _, err := publishRelease(context.Background(), config, "1.2.0"); err == nil {
+		t.Fatal("publishRelease succeeded with a missing asset")
+	}
+	// 附件缺失时不访问托管平台
+	if requests := fake.recorded(); len(requests) != 0 {
+		t.Errorf("got %d requests, want none", len(requests))
+	}
+}
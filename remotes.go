@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 远程仓库角色
+const (
+	RemoteRolePrimary  = "primary"  // 主仓库，发布时推送，ghc bind 绑定的地址
+	RemoteRoleMirror   = "mirror"   // 镜像仓库，发布时同步推送
+	RemoteRoleUpstream = "upstream" // 上游仓库，只用于查看状态，不推送
+)
+
+// 远程仓库地址与配置不一致时的处理方式
+const (
+	RemoteCheckBlock = "block" // 阻止推送（默认）
+	RemoteCheckWarn  = "warn"  // 只输出警告
+)
+
+// ConfiguredRemotes 返回配置的远程仓库列表
+// 未配置 remotes 时，使用 repo 字段作为名为 origin 的主仓库
+func (c *Config) ConfiguredRemotes() []RemoteConfig {
+	if len(c.Remotes) == 0 {
+		if c.Repo == "" {
+			return nil
+		}
+		return []RemoteConfig{{Name: "origin", URL: c.Repo, Role: RemoteRolePrimary}}
+	}
+
+	remotes := make([]RemoteConfig, 0, len(c.Remotes))
+	for _, remote := range c.Remotes {
+		if remote.Role == "" {
+			remote.Role = RemoteRoleMirror
+		}
+		remotes = append(remotes, remote)
+	}
+	return remotes
+}
+
+// PrimaryRemote 返回主远程仓库，没有声明 primary 时使用第一个远程仓库
+func (c *Config) PrimaryRemote() (RemoteConfig, bool) {
+	remotes := c.ConfiguredRemotes()
+	for _, remote := range remotes {
+		if remote.Role == RemoteRolePrimary {
+			return remote, true
+		}
+	}
+	if len(remotes) > 0 {
+		return remotes[0], true
+	}
+	return RemoteConfig{}, false
+}
+
+// PushRemotes 返回发布时需要推送的远程仓库，主仓库排在最前
+func (c *Config) PushRemotes() []RemoteConfig {
+	primary, ok := c.PrimaryRemote()
+	if !ok {
+		return nil
+	}
+
+	remotes := []RemoteConfig{primary}
+	for _, remote := range c.ConfiguredRemotes() {
+		if remote.Name != primary.Name && remote.Role == RemoteRoleMirror {
+			remotes = append(remotes, remote)
+		}
+	}
+	return remotes
+}
+
+// validateRemotes 检查远程仓库配置是否有效
+func validateRemotes(remotes []RemoteConfig) error {
+	names := make(map[string]bool, len(remotes))
+	primaries := 0
+	for _, remote := range remotes {
+		if remote.Name == "" {
+			return errors.New(msg("remotes.missing_name"))
+		}
+		if remote.URL == "" {
+			return fmt.Errorf(msg("remotes.missing_url"), remote.Name)
+		}
+		if names[remote.Name] {
+			return fmt.Errorf(msg("remotes.duplicate"), remote.Name)
+		}
+		names[remote.Name] = true
+
+		switch remote.Role {
+		case RemoteRolePrimary:
+			primaries++
+		case "", RemoteRoleMirror, RemoteRoleUpstream:
+		default:
+			return fmt.Errorf(msg("remotes.invalid_role"), remote.Name, remote.Role)
+		}
+	}
+	if primaries > 1 {
+		return errors.New(msg("remotes.multiple_primary"))
+	}
+	return nil
+}
+
+// remoteMismatch 配置的仓库地址与实际地址不一致的记录
+type remoteMismatch struct {
+	Remote   string
+	Expected string // 期望的地址
+	Actual   string // 实际的地址
+	Source   string // 期望地址的来源文件
+}
+
+// String 返回不一致的说明
+func (m remoteMismatch) String() string {
+	if m.Source == RepoLockFile {
+		return msg("remotes.lock_mismatch", RepoLockFile, m.Expected, projectConfigFile(), m.Remote, m.Actual)
+	}
+	return msg("remotes.git_mismatch", m.Source, m.Remote, m.Expected, m.Actual)
+}
+
+// findRemoteMismatches 比较配置、.repo.lock 与 git 实际的远程地址
+// 地址比较前会先规范化，忽略 https 与 git@ 形式、.git 后缀和大小写的差异
+func findRemoteMismatches(gitOps *GitOperations, config *Config) []remoteMismatch {
+	var mismatches []remoteMismatch
+	for _, remote := range config.ConfiguredRemotes() {
+		actual, err := gitOps.GetRemoteURL(remote.Name)
+		if err != nil {
+			continue // 尚未添加到 git 的远程仓库不算不一致
+		}
+		if !sameRepoURL(actual, remote.URL) {
+			mismatches = append(mismatches, remoteMismatch{
+				Remote:   remote.Name,
+				Expected: remote.URL,
+				Actual:   actual,
+				Source:   projectConfigFile(),
+			})
+		}
+	}
+
+	if lock, err := LoadRepoLock(); err == nil && lock.Repo != "" {
+		if primary, ok := config.PrimaryRemote(); ok && !sameRepoURL(lock.Repo, primary.URL) {
+			mismatches = append(mismatches, remoteMismatch{
+				Remote:   primary.Name,
+				Expected: lock.Repo,
+				Actual:   primary.URL,
+				Source:   RepoLockFile,
+			})
+		}
+	}
+	return mismatches
+}
+
+// checkRemoteConsistency 检查远程地址是否一致，按 remote_check 配置警告或阻止
+func checkRemoteConsistency(gitOps *GitOperations, config *Config) error {
+	mismatches := findRemoteMismatches(gitOps, config)
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	fmt.Println(msg("remotes.mismatch_header"))
+	for _, mismatch := range mismatches {
+		fmt.Printf("  - %s\n", mismatch)
+	}
+	fmt.Println(msg("remotes.fix_hint"))
+
+	switch config.RemoteCheck {
+	case RemoteCheckWarn:
+		return nil
+	case "", RemoteCheckBlock:
+		return configError("%s", msg("remotes.blocked"))
+	default:
+		return configError(msg("remotes.invalid_check"), config.RemoteCheck)
+	}
+}
+
+// fixRemotes 把 git 远程仓库改为配置中的地址，并同步 .repo.lock 绑定的仓库
+func fixRemotes(gitOps *GitOperations, config *Config) error {
+	for _, remote := range config.ConfiguredRemotes() {
+		actual, err := gitOps.GetRemoteURL(remote.Name)
+		switch {
+		case err != nil:
+			fmt.Println(msg("remotes.adding", remote.Name, remote.URL))
+			if err := gitOps.AddRemote(remote.Name, remote.URL); err != nil {
+				return err
+			}
+		case !sameRepoURL(actual, remote.URL):
+			fmt.Println(msg("remotes.updating", remote.Name, actual, remote.URL))
+			if err := gitOps.SetRemoteURL(remote.Name, remote.URL); err != nil {
+				return err
+			}
+		}
+	}
+
+	primary, ok := config.PrimaryRemote()
+	if !ok {
+		return nil
+	}
+	lock, err := LoadRepoLock()
+	if isRepoLockCorrupt(err) {
+		return err
+	}
+	if err != nil {
+		lock = &RepoLock{Branch: config.Branch}
+	}
+	if !sameRepoURL(lock.Repo, primary.URL) {
+		fmt.Println(msg("remotes.updating_lock", RepoLockFile, primary.URL))
+		lock.Repo = primary.URL
+		if err := SaveRepoLock(lock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remoteResult 单个远程仓库的操作结果
+type remoteResult struct {
+	Remote string
+	Err    error
+}
+
+// forEachRemote 依次对每个远程仓库执行操作并收集结果，ctx 取消后不再处理剩余的远程仓库
+func forEachRemote(ctx context.Context, remotes []RemoteConfig, fn func(remote RemoteConfig) error) []remoteResult {
+	results := make([]remoteResult, 0, len(remotes))
+	for _, remote := range remotes {
+		err := ctx.Err()
+		if err == nil {
+			err = fn(remote)
+		} else {
+			err = fmt.Errorf(msg("remotes.canceled"), context.Cause(ctx))
+		}
+		results = append(results, remoteResult{Remote: remote.Name, Err: err})
+	}
+	return results
+}
+
+// remoteOutcome 单个远程仓库操作结果的输出形式
+type remoteOutcome struct {
+	Remote string `json:"remote" yaml:"remote"`
+	OK     bool   `json:"ok" yaml:"ok"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// remoteOutcomes 把操作结果转换为输出形式
+func remoteOutcomes(results []remoteResult) []remoteOutcome {
+	outcomes := make([]remoteOutcome, 0, len(results))
+	for _, result := range results {
+		outcome := remoteOutcome{Remote: result.Remote, OK: result.Err == nil}
+		if result.Err != nil {
+			outcome.Error = result.Err.Error()
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+// printRemoteResults 输出每个远程仓库的操作结果
+func printRemoteResults(results []remoteResult) {
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("  ✗ %s: %v\n", result.Remote, result.Err)
+		} else {
+			fmt.Printf("  ✓ %s\n", result.Remote)
+		}
+	}
+}
+
+// countRemoteFailures 统计失败的远程仓库数量
+func countRemoteFailures(results []remoteResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// succeededRemotes 返回操作成功的远程仓库
+func succeededRemotes(remotes []RemoteConfig, results []remoteResult) []RemoteConfig {
+	var succeeded []RemoteConfig
+	for i, result := range results {
+		if result.Err == nil {
+			succeeded = append(succeeded, remotes[i])
+		}
+	}
+	return succeeded
+}
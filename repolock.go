@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoLockCorruptError 仓库锁定文件损坏，无法解析或校验和不匹配
+type RepoLockCorruptError struct {
+	Reason string
+}
+
+func (e *RepoLockCorruptError) Error() string {
+	return msg("repolock.corrupt", RepoLockFile, e.Reason)
+}
+
+// isRepoLockCorrupt 判断错误是否为锁定文件损坏
+func isRepoLockCorrupt(err error) bool {
+	var corrupt *RepoLockCorruptError
+	return errors.As(err, &corrupt)
+}
+
+// checksum 计算除 Checksum 之外所有字段的校验和
+func (l *RepoLock) checksum() (string, error) {
+	unsigned := *l
+	unsigned.Checksum = ""
+	data, err := marshalYAML(&unsigned)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// legacyRepoLockKeys 加入校验和之前的版本每次都会写入的字段
+var legacyRepoLockKeys = []string{"repo", "branch", "current_version", "last_updated"}
+
+// isLegacyRepoLock 判断没有校验和的锁定文件是否为旧版本写入的完整文件：
+// 旧版本总是写入 legacyRepoLockKeys 中的全部字段，且不会写入 checksum
+func isLegacyRepoLock(data []byte) bool {
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+	if _, ok := fields["checksum"]; ok {
+		return false
+	}
+	for _, key := range legacyRepoLockKeys {
+		if _, ok := fields[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// repairAsked 本次运行中是否已经询问过重建，避免同一命令重复询问
+var repairAsked bool
+
+// repairRepoLock 锁定文件损坏时，在终端中询问是否根据 git 状态重建；
+// 不在终端中运行或用户拒绝时返回原错误
+func repairRepoLock(corrupt *RepoLockCorruptError) (*RepoLock, error) {
+	if repairAsked || !stdinIsTerminal() {
+		return nil, corrupt
+	}
+	repairAsked = true
+
+	fmt.Println(msg("repolock.corrupt_short", RepoLockFile, corrupt.Reason))
+	if !confirm(msg("repolock.rebuild_confirm")) {
+		return nil, corrupt
+	}
+
+	lock, err := rebuildRepoLock()
+	if err != nil {
+		return nil, fmt.Errorf(msg("repolock.rebuild_failed"), err)
+	}
+	if err := SaveRepoLock(lock); err != nil {
+		return nil, err
+	}
+	fmt.Println(msg("repolock.rebuilt", RepoLockFile))
+	return lock, nil
+}
+
+// rebuildRepoLock 根据 git 状态生成锁定信息：最新标签作为当前版本，
+// 当前分支作为绑定的分支，主远程仓库（默认为 origin）的地址作为绑定的仓库
+func rebuildRepoLock() (*RepoLock, error) {
+	gitOps, err := NewGitOperations(".")
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &RepoLock{LastUpdated: time.Now().Format(time.RFC3339)}
+	// git 中没有发布历史，原文件还能解析时保留其中的记录
+	if data, err := ioutil.ReadFile(RepoLockFile); err == nil {
+		var previous RepoLock
+		if yaml.Unmarshal(data, &previous) == nil {
+			lock.History = previous.History
+		}
+	}
+	if branch, err := gitOps.GetCurrentBranch(); err == nil {
+		lock.Branch = branch
+	}
+	if tag, err := gitOps.GetLatestTag(""); err == nil {
+		lock.CurrentVersion = tag
+	}
+
+	remote := "origin"
+	config, configErr := LoadConfig()
+	if configErr == nil {
+		if primary, ok := config.PrimaryRemote(); ok {
+			remote = primary.Name
+		}
+	}
+	if url, err := gitOps.GetRemoteURL(remote); err == nil {
+		lock.Repo = url
+	} else if configErr == nil {
+		lock.Repo = config.Repo
+	}
+	if lock.Branch == "" && configErr == nil {
+		lock.Branch = config.Branch
+	}
+	return lock, nil
+}
+
+// stdinIsTerminal 判断标准输入是否为终端
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// confirm 在终端中询问是否继续，默认为否
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// handleLockRebuild 处理 ghc lock rebuild，根据 git 状态重建 仓库锁定文件
+func handleLockRebuild() error {
+	lock, err := rebuildRepoLock()
+	if err != nil {
+		return gitError(msg("repolock.rebuild_failed"), err)
+	}
+	if err := SaveRepoLock(lock); err != nil {
+		return configError("%w", err)
+	}
+
+	result := &lockRebuildResult{Repo: lock.Repo, Branch: lock.Branch, CurrentVersion: lock.CurrentVersion}
+	return emitResult(result, func() {
+		fmt.Println(msg("repolock.rebuilt", RepoLockFile))
+		fmt.Println(msg("repolock.rebuilt_repo", lock.Repo))
+		fmt.Println(msg("repolock.rebuilt_branch", lock.Branch))
+		fmt.Println(msg("repolock.rebuilt_version", lock.CurrentVersion))
+	})
+}
+
+// lockRebuildResult ghc lock rebuild 的结果
+type lockRebuildResult struct {
+	Repo           string `json:"repo" yaml:"repo"`
+	Branch         string `json:"branch" yaml:"branch"`
+	CurrentVersion string `json:"current_version" yaml:"current_version"`
+}
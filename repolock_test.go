@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// writeRepoLockFile 把 data 写入当前目录的 .repo.lock
+func writeRepoLockFile(t *testing.T, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(RepoLockFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadRepoLockDetectsTruncation(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	lock := &RepoLock{
+		Repo:           "https://github.com/owner/repo.git",
+		Branch:         "main",
+		CurrentVersion: "v1.2.0",
+		LastUpdated:    "2026-01-02T03:04:05Z",
+		History: []ReleaseRecord{
+			{Version: "1.1.0", Tag: "v1.1.0", Commit: "abc123", Branch: "main", Outcome: "success"},
+			{Version: "1.2.0", Tag: "v1.2.0", Commit: "def456", Branch: "main", Outcome: "success"},
+		},
+	}
+	if err := SaveRepoLock(lock); err != nil {
+		t.Fatalf("SaveRepoLock: %v", err)
+	}
+	data, err := os.ReadFile(RepoLockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readRepoLock(); err != nil || got.CurrentVersion != "v1.2.0" || len(got.History) != 2 {
+		t.Fatalf("readRepoLock = %+v, %v", got, err)
+	}
+
+	// 在任意位置截断都视为损坏，只去掉末尾换行符时内容不变
+	for n := 0; n < len(data)-1; n++ {
+		writeRepoLockFile(t, data[:n])
+		if _, err := readRepoLock(); !isRepoLockCorrupt(err) {
+			t.Errorf("truncated to %d bytes: err = %v, want corrupt\n%s", n, err, data[:n])
+		}
+	}
+}
+
+func TestReadRepoLockLegacyFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// 加入校验和之前写入的完整文件仍然可以读取
+	writeRepoLockFile(t, []byte("repo: https://github.com/owner/repo.git\nbranch: main\ncurrent_version: v1.0.0\nlast_updated: \"2025-01-01T00:00:00Z\"\n"))
+	lock, err := readRepoLock()
+	if err != nil {
+		t.Fatalf("readRepoLock(legacy): %v", err)
+	}
+	if lock.CurrentVersion != "v1.0.0" {
+		t.Errorf("CurrentVersion = %q", lock.CurrentVersion)
+	}
+
+	// 缺少旧版本总会写入的字段时不是旧文件
+	writeRepoLockFile(t, []byte("repo: https://github.com/owner/repo.git\nbranch: main\n"))
+	if _, err := readRepoLock(); !isRepoLockCorrupt(err) {
+		t.Errorf("partial file without checksum: err = %v, want corrupt", err)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RepoURL 解析后的仓库地址
+type RepoURL struct {
+	Scheme string // https、http、ssh 或 file（本地仓库）
+	Host   string // 主机名，本地仓库为空
+	Port   string // 端口，未指定时为空
+	Path   string // 仓库路径，例如 owner/repo；本地仓库为绝对路径
+}
+
+// scpLikeURL 匹配 git@host:owner/repo 形式的 SSH 地址
+var scpLikeURL = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ParseRepoURL 解析仓库地址，支持以下形式：
+//
+//	https://host/owner/repo.git
+//	ssh://git@host:2222/owner/repo.git
+//	git@host:owner/repo.git
+//	file:///path/to/repo.git
+//	/path/to/repo 或相对路径
+func ParseRepoURL(raw string) (RepoURL, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return RepoURL{}, errors.New(msg("repourl.empty"))
+	}
+
+	if strings.Contains(s, "://") {
+		parsed, err := url.Parse(s)
+		if err != nil {
+			return RepoURL{}, fmt.Errorf(msg("repourl.invalid"), raw, err)
+		}
+		scheme := strings.ToLower(parsed.Scheme)
+		switch scheme {
+		case "file":
+			return RepoURL{Scheme: "file", Path: cleanLocalPath(parsed.Path)}, nil
+		case "http", "https", "ssh", "git":
+		case "git+ssh", "ssh+git":
+			scheme = "ssh"
+		default:
+			return RepoURL{}, fmt.Errorf(msg("repourl.unsupported_scheme"), parsed.Scheme)
+		}
+		if parsed.Hostname() == "" {
+			return RepoURL{}, fmt.Errorf(msg("repourl.missing_host"), raw)
+		}
+		return RepoURL{
+			Scheme: scheme,
+			Host:   parsed.Hostname(),
+			Port:   parsed.Port(),
+			Path:   cleanRepoPath(parsed.Path),
+		}, nil
+	}
+
+	// 排除 Windows 盘符路径（例如 C:\repo）
+	if m := scpLikeURL.FindStringSubmatch(s); m != nil && len(m[1]) > 1 {
+		return RepoURL{Scheme: "ssh", Host: m[1], Path: cleanRepoPath(m[2])}, nil
+	}
+
+	return RepoURL{Scheme: "file", Path: cleanLocalPath(s)}, nil
+}
+
+// isExplicitLocalPath 判断地址是否明确写成本地路径：绝对路径、./ 或 ../ 开头的相对路径，或 file:// 地址
+// ParseRepoURL 把无法识别的字符串都当作本地路径，校验配置时用它排除拼写错误的地址
+func isExplicitLocalPath(raw string) bool {
+	s := strings.TrimSpace(raw)
+	if strings.HasPrefix(strings.ToLower(s), "file://") || filepath.IsAbs(s) {
+		return true
+	}
+	s = filepath.ToSlash(s)
+	return strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../")
+}
+
+// cleanRepoPath 去掉仓库路径首尾的斜杠和 .git 后缀
+func cleanRepoPath(path string) string {
+	path = strings.Trim(path, "/")
+	return strings.TrimSuffix(path, ".git")
+}
+
+// cleanLocalPath 把本地仓库路径转换为绝对路径，去掉 .git 后缀，/x/repo/.git 和 /x/repo.git 都对应 /x/repo
+func cleanLocalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = strings.TrimSuffix(filepath.ToSlash(path), ".git")
+	return filepath.ToSlash(filepath.Clean(filepath.FromSlash(path)))
+}
+
+// IsLocal 判断是否为本地仓库
+func (u RepoURL) IsLocal() bool {
+	return u.Host == ""
+}
+
+// Owner 返回仓库所有者，GitLab 的子群组会包含在内，例如 group/subgroup
+func (u RepoURL) Owner() string {
+	if u.IsLocal() {
+		return ""
+	}
+	if i := strings.LastIndex(u.Path, "/"); i >= 0 {
+		return u.Path[:i]
+	}
+	return ""
+}
+
+// Name 返回仓库名
+func (u RepoURL) Name() string {
+	return u.Path[strings.LastIndex(u.Path, "/")+1:]
+}
+
+// WebURL 返回托管平台的网页地址，例如 https://gitlab.example.com
+// SSH 地址的端口不适用于网页访问，因此只有 http(s) 地址保留端口
+func (u RepoURL) WebURL() string {
+	if u.IsLocal() {
+		return ""
+	}
+	scheme := "https"
+	host := u.Host
+	if u.Scheme == "http" || u.Scheme == "https" {
+		scheme = u.Scheme
+		if u.Port != "" {
+			host += ":" + u.Port
+		}
+	}
+	return scheme + "://" + host
+}
+
+// String 返回规范化后的地址，用于比较两个仓库地址是否指向同一仓库
+func (u RepoURL) String() string {
+	if u.IsLocal() {
+		return u.Path
+	}
+	return u.Host + "/" + u.Path
+}
+
+// normalizeRepoURL 规范化仓库地址：忽略协议（https 与 git@）、.git 后缀和大小写差异
+func normalizeRepoURL(raw string) string {
+	parsed, err := ParseRepoURL(raw)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(raw))
+	}
+	return strings.ToLower(parsed.String())
+}
+
+// sameRepoURL 判断两个仓库地址是否指向同一仓库
+func sameRepoURL(a, b string) bool {
+	return normalizeRepoURL(a) == normalizeRepoURL(b)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want RepoURL
+	}{
+		{"https://github.com/owner/repo.git", RepoURL{Scheme: "https", Host: "github.com", Path: "owner/repo"}},
+		{"ssh://git@gitlab.com:2222/group/sub/repo.git", RepoURL{Scheme: "ssh", Host: "gitlab.com", Port: "2222", Path: "group/sub/repo"}},
+		{"git@github.com:owner/repo.git", RepoURL{Scheme: "ssh", Host: "github.com", Path: "owner/repo"}},
+		{"file:///x/repo.git", RepoURL{Scheme: "file", Path: "/x/repo"}},
+	}
+	for _, tc := range tests {
+		got, err := ParseRepoURL(tc.raw)
+		if err != nil {
+			t.Errorf("ParseRepoURL(%q): %v", tc.raw, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseRepoURL(%q) = %+v, want %+v", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestCleanLocalPath(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+	for _, path := range []string{"repo", "repo/", "repo.git", "repo/.git", "repo/.git/", "./repo/../repo/.git"} {
+		if got, want := cleanLocalPath(root+"/"+path), root+"/repo"; got != want {
+			t.Errorf("cleanLocalPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCheckRepoURLFormat(t *testing.T) {
+	valid := []string{
+		"https://github.com/owner/repo.git",
+		"git@github.com:owner/repo.git",
+		"file:///srv/git/repo.git",
+		filepath.Join(t.TempDir(), "repo.git"),
+		"./repo",
+		"../mirrors/repo.git",
+	}
+	for _, value := range valid {
+		if err := checkFormat("repo-url", value); err != nil {
+			t.Errorf("checkFormat(repo-url, %q): %v", value, err)
+		}
+	}
+
+	invalid := []string{
+		"not a url",
+		"repo",
+		"github.com/owner/repo",
+		"https://github.com/repo",
+		"ftp://github.com/owner/repo",
+	}
+	for _, value := range invalid {
+		if err := checkFormat("repo-url", value); err == nil {
+			t.Errorf("checkFormat(repo-url, %q) succeeded, want an error", value)
+		}
+	}
+}
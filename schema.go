@@ -0,0 +1,464 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaNode 配置项的模式描述，由 Config 结构体和 schemaRules 生成
+type schemaNode struct {
+	Type        string // object、map、array、string、integer、boolean
+	Description string // 说明在 messages 中的键，没有说明时为空
+	Enum        []string
+	Format      string // version、repo-url、url
+	Minimum     *int
+	Required    []string
+
+	Fields []schemaField // object 的字段，按结构体中的顺序排列
+	Items  *schemaNode   // array 的元素
+	Values *schemaNode   // map 的值
+}
+
+// schemaField object 中的一个字段
+type schemaField struct {
+	Name string
+	Node *schemaNode
+}
+
+// schemaRule 补充在结构体之外的约束，键为点分路径，数组元素用 [] 表示
+type schemaRule struct {
+	Enum     []string
+	Format   string
+	Minimum  *int
+	Required []string
+}
+
+// zero 和 one 作为 Minimum 的取值
+var zero, one = 0, 1
+
+var schemaRules = map[string]schemaRule{
+	"schema_version":            {Minimum: &one},
+	"repo":                      {Format: "repo-url"},
+	"version":                   {Format: "version"},
+	"pre_build.steps[]":         {Required: []string{"id", "run"}},
+	"pre_build.steps[].timeout": {Minimum: &zero},
+	"pre_build.concurrency":     {Minimum: &zero},
+	"pre_build.timeout":         {Minimum: &zero},
+	"remotes[]":                 {Required: []string{"name", "url"}},
+	"remotes[].url":             {Format: "repo-url"},
+	"remotes[].role":            {Enum: []string{RemoteRolePrimary, RemoteRoleMirror, RemoteRoleUpstream}},
+	"remote_check":              {Enum: []string{RemoteCheckBlock, RemoteCheckWarn}},
+	"forge":                     {Enum: []string{ForgeGitHub, ForgeGitLab, ForgeGitea, ForgeBitbucket}},
+	"forge_hosts.*":             {Enum: []string{ForgeGitHub, ForgeGitLab, ForgeGitea, ForgeBitbucket}},
+	"release.api_url":           {Format: "url"},
+	"logs.retention":            {Minimum: &zero},
+	"logs.tail_lines":           {Minimum: &zero},
+	"lang":                      {Enum: []string{LocaleZH, LocaleEN}},
+	"packages.*":                {Required: []string{"path"}},
+	"packages.*.version":        {Format: "version"},
+}
+
+// configSchema Config 的模式
+var configSchema = buildSchema(reflect.TypeOf(Config{}), "")
+
+// buildSchema 根据结构体的 yaml 标签生成模式，并合并 schemaRules 中的约束
+func buildSchema(t reflect.Type, path string) *schemaNode {
+	node := &schemaNode{}
+	switch t.Kind() {
+	case reflect.Struct:
+		node.Type = "object"
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			// 方案中不能再嵌套方案或定义包，也不单独记录配置版本
+			if (name == "profiles" || name == "packages" || name == "schema_version") && path != "" {
+				continue
+			}
+			node.Fields = append(node.Fields, schemaField{Name: name, Node: buildSchema(field.Type, joinSchemaPath(path, name))})
+		}
+	case reflect.Map:
+		node.Type = "map"
+		node.Values = buildSchema(t.Elem(), joinSchemaPath(path, "*"))
+	case reflect.Slice:
+		node.Type = "array"
+		node.Items = buildSchema(t.Elem(), path+"[]")
+	case reflect.Bool:
+		node.Type = "boolean"
+	case reflect.Int, reflect.Int64:
+		node.Type = "integer"
+	default:
+		node.Type = "string"
+	}
+
+	// 方案中的配置项与顶层配置项使用相同的约束
+	rulePath := path
+	if strings.HasPrefix(path, "profiles.*.") {
+		rulePath = strings.TrimPrefix(path, "profiles.*.")
+	}
+	// 说明按路径从消息目录中查找，导出时才按当前语言翻译
+	if _, ok := messages["schema."+rulePath]; ok {
+		node.Description = "schema." + rulePath
+	}
+	if rule, ok := schemaRules[rulePath]; ok {
+		node.Enum = rule.Enum
+		node.Format = rule.Format
+		node.Minimum = rule.Minimum
+		node.Required = rule.Required
+	}
+	return node
+}
+
+// joinSchemaPath 拼接点分路径
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// field 按名称查找 object 的字段
+func (n *schemaNode) field(name string) *schemaNode {
+	for _, field := range n.Fields {
+		if field.Name == name {
+			return field.Node
+		}
+	}
+	return nil
+}
+
+// ConfigError 带有文件位置的配置错误
+type ConfigError struct {
+	File    string `json:"file" yaml:"file"`
+	Line    int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column  int    `json:"column,omitempty" yaml:"column,omitempty"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"` // 出错配置项的点分路径
+	Message string `json:"message" yaml:"message"`
+}
+
+func (e *ConfigError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	}
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", location, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, e.Path, e.Message)
+}
+
+// ConfigErrors 配置文件中的全部错误
+type ConfigErrors []*ConfigError
+
+func (errs ConfigErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// yamlErrorLine 从 yaml 解析错误中提取行号
+var yamlErrorLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// parseConfigDocument 解析 YAML 文档，语法错误转换为带行号的 ConfigErrors
+func parseConfigDocument(file string, data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		var errs ConfigErrors
+		for _, line := range strings.Split(strings.TrimPrefix(err.Error(), "yaml: unmarshal errors:\n"), "\n") {
+			line = strings.TrimSpace(line)
+			if m := yamlErrorLine.FindStringSubmatch(line); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				errs = append(errs, &ConfigError{File: file, Line: n, Column: 1, Message: m[2]})
+			} else if line != "" {
+				errs = append(errs, &ConfigError{File: file, Message: strings.TrimPrefix(line, "yaml: ")})
+			}
+		}
+		return nil, errs
+	}
+	return &doc, nil
+}
+
+// validateConfigDocument 按模式检查配置文档，返回所有错误
+func validateConfigDocument(file string, doc *yaml.Node) ConfigErrors {
+	v := &schemaValidator{file: file}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		v.validate(configSchema, doc.Content[0], "")
+	} else if doc.Kind != 0 {
+		v.validate(configSchema, doc, "")
+	}
+	return v.errs
+}
+
+// schemaValidator 递归检查 YAML 节点
+type schemaValidator struct {
+	file string
+	errs ConfigErrors
+}
+
+// fail 记录一个错误
+func (v *schemaValidator) fail(node *yaml.Node, path, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ConfigError{
+		File:    v.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validate 检查节点是否符合模式
+func (v *schemaValidator) validate(schema *schemaNode, node *yaml.Node, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	// 空值等同于零值
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch schema.Type {
+	case "object", "map":
+		if node.Kind != yaml.MappingNode {
+			v.fail(node, path, msg("schema.expected_object"), describeNode(node))
+			return
+		}
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinSchemaPath(path, key.Value)
+			if seen[key.Value] {
+				v.fail(key, keyPath, "%s", msg("schema.duplicate_key"))
+				continue
+			}
+			seen[key.Value] = true
+
+			if schema.Type == "map" {
+				v.validate(schema.Values, value, keyPath)
+				continue
+			}
+			field := schema.field(key.Value)
+			if field == nil {
+				if suggestion := suggestField(schema, key.Value); suggestion != "" {
+					v.fail(key, keyPath, msg("schema.unknown_key_suggest"), suggestion)
+				} else {
+					v.fail(key, keyPath, "%s", msg("schema.unknown_key"))
+				}
+				continue
+			}
+			v.validate(field, value, keyPath)
+		}
+		for _, name := range schema.Required {
+			if !seen[name] {
+				v.fail(node, path, msg("schema.missing_required"), name)
+			}
+		}
+
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.fail(node, path, msg("schema.expected_array"), describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			v.fail(node, path, msg("schema.expected_boolean"), describeNode(node))
+		}
+
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			v.fail(node, path, msg("schema.expected_integer"), describeNode(node))
+			return
+		}
+		n, err := strconv.Atoi(node.Value)
+		if err != nil {
+			v.fail(node, path, msg("schema.invalid_integer"), node.Value)
+			return
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			v.fail(node, path, msg("schema.below_minimum"), *schema.Minimum, n)
+		}
+
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			v.fail(node, path, msg("schema.expected_string"), describeNode(node))
+			return
+		}
+		if len(schema.Enum) > 0 && node.Value != "" && !containsString(schema.Enum, node.Value) {
+			v.fail(node, path, msg("schema.invalid_enum"), node.Value, strings.Join(schema.Enum, msg("common.list_separator")))
+		}
+		if node.Value != "" {
+			if err := checkFormat(schema.Format, node.Value); err != nil {
+				v.fail(node, path, "%v", err)
+			}
+		}
+	}
+}
+
+// versionPattern 语义化版本号，允许 v 前缀
+var versionPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// checkFormat 检查字符串格式
+func checkFormat(format, value string) error {
+	switch format {
+	case "version":
+		if !versionPattern.MatchString(value) {
+			return fmt.Errorf(msg("schema.invalid_version"), value)
+		}
+	case "repo-url":
+		repo, err := ParseRepoURL(value)
+		if err != nil {
+			return err
+		}
+		if repo.IsLocal() {
+			if !isExplicitLocalPath(value) || repo.Name() == "" {
+				return fmt.Errorf(msg("schema.invalid_local_repo"), value)
+			}
+		} else if repo.Owner() == "" || repo.Name() == "" {
+			return fmt.Errorf(msg("schema.invalid_repo_url"), value)
+		}
+	case "url":
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf(msg("schema.invalid_url"), value)
+		}
+	}
+	return nil
+}
+
+// describeNode 描述节点的实际类型，用于错误信息
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return msg("schema.kind_object")
+	case yaml.SequenceNode:
+		return msg("schema.kind_array")
+	}
+	switch node.Tag {
+	case "!!bool":
+		return msg("schema.kind_boolean", node.Value)
+	case "!!int", "!!float":
+		return msg("schema.kind_number", node.Value)
+	}
+	return msg("schema.kind_string", node.Value)
+}
+
+// containsString 判断切片中是否包含指定字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestField 为拼写错误的配置项查找最接近的字段名
+func suggestField(schema *schemaNode, name string) string {
+	best, bestDistance := "", 3
+	for _, field := range schema.Fields {
+		if d := editDistance(name, field.Name); d < bestDistance {
+			best, bestDistance = field.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// minInt 返回最小值
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// ConfigJSONSchema 把配置模式导出为 JSON Schema，供编辑器补全和校验
+func ConfigJSONSchema() map[string]interface{} {
+	schema := jsonSchemaFor(configSchema)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = ConfigFile
+	return schema
+}
+
+// jsonSchemaFor 把模式节点转换为 JSON Schema
+func jsonSchemaFor(node *schemaNode) map[string]interface{} {
+	out := map[string]interface{}{}
+	if node.Description != "" {
+		out["description"] = msg(node.Description)
+	}
+
+	switch node.Type {
+	case "object":
+		out["type"] = "object"
+		properties := map[string]interface{}{}
+		for _, field := range node.Fields {
+			properties[field.Name] = jsonSchemaFor(field.Node)
+		}
+		out["properties"] = properties
+		out["additionalProperties"] = false
+		if len(node.Required) > 0 {
+			required := append([]string{}, node.Required...)
+			sort.Strings(required)
+			out["required"] = required
+		}
+	case "map":
+		out["type"] = "object"
+		out["additionalProperties"] = jsonSchemaFor(node.Values)
+	case "array":
+		out["type"] = "array"
+		out["items"] = jsonSchemaFor(node.Items)
+	default:
+		out["type"] = node.Type
+	}
+
+	if len(node.Enum) > 0 {
+		out["enum"] = node.Enum
+	}
+	if node.Minimum != nil {
+		out["minimum"] = *node.Minimum
+	}
+	switch node.Format {
+	case "version":
+		out["pattern"] = versionPattern.String()
+	case "url":
+		out["format"] = "uri"
+	}
+	return out
+}
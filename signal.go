@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// processKillGrace 发送终止信号后等待进程退出的宽限期，超时后强制终止
+const processKillGrace = 5 * time.Second
+
+// signalError 表示 ghc 因收到信号而取消
+type signalError struct {
+	sig os.Signal
+}
+
+func (e signalError) Error() string {
+	return msg("signal.received", e.sig)
+}
+
+// withInterrupt 返回在收到 SIGINT/SIGTERM 时取消的 context
+// 收到第一次信号后恢复默认处理，再次按 Ctrl-C 将直接退出
+func withInterrupt(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			fmt.Println()
+			fmt.Println(msg("signal.stopping", sig))
+			cancel(signalError{sig: sig})
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
+
+// runProcess 运行命令，ctx 取消时把收到的信号（默认 SIGTERM）转发给命令，宽限期后仍未退出则强制终止。
+// 构建命令和预编译步骤在独立进程组中运行，取消时终止整个进程组；
+// git 可能在终端中询问 HTTPS 凭据或 SSH 密码，留在 ghc 所在的前台进程组中，
+// 否则读取终端时会收到 SIGTTIN 而挂起，按下 Ctrl-C 时终端会直接把 SIGINT 发给它
+func runProcess(ctx context.Context, cmd *exec.Cmd) error {
+	group := !isInteractiveCommand(cmd)
+	if group {
+		setProcessGroup(cmd)
+	}
+	signal := func(sig os.Signal) {
+		if group {
+			signalProcessGroup(cmd, sig)
+		} else {
+			signalProcess(cmd, sig)
+		}
+	}
+	kill := func() {
+		if group {
+			killProcessGroup(cmd)
+		} else {
+			cmd.Process.Kill()
+		}
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	waitDone := make(chan error, 1)
+	go func() {
+		waitDone <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-waitDone:
+		return err
+	case <-ctx.Done():
+	}
+
+	var sig os.Signal = syscall.SIGTERM
+	var sigErr signalError
+	if errors.As(context.Cause(ctx), &sigErr) {
+		sig = sigErr.sig
+	}
+	signal(sig)
+
+	// 即使直接子进程已经退出，孙进程也可能仍在运行，宽限期结束后统一强制终止
+	timer := time.NewTimer(processKillGrace)
+	defer timer.Stop()
+	select {
+	case err := <-waitDone:
+		if group {
+			killProcessGroup(cmd)
+		}
+		return err
+	case <-timer.C:
+	}
+
+	kill()
+	return <-waitDone
+}
+
+// isInteractiveCommand 判断命令是否可能需要从终端读取输入，目前只有 git
+func isInteractiveCommand(cmd *exec.Cmd) bool {
+	name := strings.ToLower(filepath.Base(cmd.Path))
+	return strings.TrimSuffix(name, ".exe") == "git"
+}
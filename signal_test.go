@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestIsInteractiveCommand(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"git", true},
+		{"/usr/bin/git", true},
+		{"/usr/local/bin/git.exe", true},
+		{"/bin/sh", false},
+		{"go", false},
+	}
+	for _, tc := range tests {
+		cmd := &exec.Cmd{Path: tc.path}
+		if got := isInteractiveCommand(cmd); got != tc.want {
+			t.Errorf("isInteractiveCommand(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
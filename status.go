@@ -0,0 +1,404 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// statusReport ghc status 的结果，--json 时原样输出
+type statusReport struct {
+	Profile       string         `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Repo          string         `json:"repo" yaml:"repo"`
+	Branch        string         `json:"branch" yaml:"branch"`
+	Version       string         `json:"version" yaml:"version"`
+	TagPrefix     string         `json:"tag_prefix" yaml:"tag_prefix"`
+	AutoPush      bool           `json:"auto_push" yaml:"auto_push"`
+	BuildCommand  string         `json:"build_command" yaml:"build_command"`
+	PreBuildSteps int            `json:"pre_build_steps,omitempty" yaml:"pre_build_steps,omitempty"`
+	PreBuildError string         `json:"pre_build_error,omitempty" yaml:"pre_build_error,omitempty"`
+	Release       *ReleaseConfig `json:"-" yaml:"-"`
+	Git           *gitState      `json:"git,omitempty" yaml:"git,omitempty"`
+	NextVersion   string         `json:"next_version,omitempty" yaml:"next_version,omitempty"`
+	NextTag       string         `json:"next_tag,omitempty" yaml:"next_tag,omitempty"`
+	Lock          lockState      `json:"lock" yaml:"lock"`
+	Remote        *remoteState   `json:"remote,omitempty" yaml:"remote,omitempty"`
+	Publish       *publishState  `json:"publish,omitempty" yaml:"publish,omitempty"`
+	Remotes       []remoteStatus `json:"remotes,omitempty" yaml:"remotes,omitempty"`
+}
+
+// gitState 工作区和标签的状态
+type gitState struct {
+	Head            string `json:"head" yaml:"head"`         // 分支名，分离状态时为标签或提交哈希
+	Detached        bool   `json:"detached" yaml:"detached"` // HEAD 不在分支上
+	Dirty           bool   `json:"dirty" yaml:"dirty"`
+	ChangedFiles    int    `json:"changed_files" yaml:"changed_files"`
+	Upstream        string `json:"upstream,omitempty" yaml:"upstream,omitempty"` // 上游分支，例如 origin/main
+	Ahead           int    `json:"ahead" yaml:"ahead"`
+	Behind          int    `json:"behind" yaml:"behind"`
+	LatestTag       string `json:"latest_tag,omitempty" yaml:"latest_tag,omitempty"`
+	CommitsSinceTag int    `json:"commits_since_tag" yaml:"commits_since_tag"`
+}
+
+// 锁定文件的状态
+const (
+	lockOK      = "ok"      // 与配置一致
+	lockDrift   = "drift"   // 与配置不一致
+	lockMissing = "missing" // 文件不存在
+	lockCorrupt = "corrupt" // 无法解析或校验和不匹配
+)
+
+// lockState .repo.lock 与配置的比较结果
+type lockState struct {
+	State string           `json:"state" yaml:"state"`
+	Error string           `json:"error,omitempty" yaml:"error,omitempty"`
+	Drift []lockFieldDrift `json:"drift,omitempty" yaml:"drift,omitempty"`
+}
+
+// lockFieldDrift 锁定文件中与配置不一致的字段
+type lockFieldDrift struct {
+	Field  string `json:"field" yaml:"field"`
+	Lock   string `json:"lock" yaml:"lock"`
+	Config string `json:"config" yaml:"config"`
+}
+
+// remoteState 主远程仓库在 git 中的地址与 Config.Repo 的比较结果
+type remoteState struct {
+	Name    string `json:"name" yaml:"name"`
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"` // git 中的地址，未添加时为空
+	Config  string `json:"config" yaml:"config"`
+	Matches bool   `json:"matches" yaml:"matches"`
+}
+
+// publishState 是否有尚未发布的内容
+type publishState struct {
+	Pending           bool   `json:"pending" yaml:"pending"`
+	VersionTagged     bool   `json:"version_tagged" yaml:"version_tagged"` // 配置中的版本已经有标签
+	UnreleasedCommits int    `json:"unreleased_commits" yaml:"unreleased_commits"`
+	LastVersion       string `json:"last_version,omitempty" yaml:"last_version,omitempty"`
+	LastOutcome       string `json:"last_outcome,omitempty" yaml:"last_outcome,omitempty"`
+}
+
+// remoteStatus 配置的远程仓库及当前分支的同步状态
+type remoteStatus struct {
+	Name      string `json:"name" yaml:"name"`
+	Role      string `json:"role" yaml:"role"`
+	URL       string `json:"url" yaml:"url"`
+	ActualURL string `json:"actual_url,omitempty" yaml:"actual_url,omitempty"`
+	Tracked   bool   `json:"tracked" yaml:"tracked"`
+	Ahead     int    `json:"ahead" yaml:"ahead"`
+	Behind    int    `json:"behind" yaml:"behind"`
+}
+
+// handleStatus 处理状态查看命令，--json 与 --output json 相同
+func handleStatus() error {
+	config, err := LoadConfig()
+	if err != nil {
+		return configError(msg("common.load_config_failed"), err)
+	}
+
+	report := collectStatus(config)
+	return emitResult(report, func() { printStatus(report) })
+}
+
+// collectStatus 收集配置、git、锁定文件和发布状态
+func collectStatus(config *Config) *statusReport {
+	report := &statusReport{
+		Profile:      activeProfile,
+		Repo:         config.Repo,
+		Branch:       config.Branch,
+		Version:      config.Version,
+		TagPrefix:    config.TagPrefix,
+		AutoPush:     config.AutoPush,
+		BuildCommand: config.BuildCommand,
+	}
+	if config.PreBuild.Enabled {
+		if nodes, err := buildPreBuildGraph(config.PreBuild); err == nil {
+			report.PreBuildSteps = len(nodes)
+		} else {
+			report.PreBuildError = err.Error()
+		}
+	}
+	if config.Release.Enabled {
+		report.Release = &config.Release
+	}
+	report.Lock = compareRepoLock(config)
+
+	if !IsGitRepository(projectDir) {
+		return report
+	}
+	gitOps, err := NewGitOperations(projectDir)
+	if err != nil {
+		return report
+	}
+
+	report.Git = collectGitState(gitOps, config)
+	report.NextVersion, report.NextTag = nextReleaseVersion(config, report.Git.LatestTag)
+	report.Remote = compareRemote(gitOps, config)
+	report.Publish = collectPublishState(gitOps, config, report.Git)
+	report.Remotes = collectRemoteStatus(gitOps, config, report.Git)
+	return report
+}
+
+// collectGitState 收集 HEAD、工作区、上游分支和最近标签的状态
+func collectGitState(gitOps *GitOperations, config *Config) *gitState {
+	state := &gitState{}
+	state.Head, state.Detached, _ = gitOps.HeadState()
+	if changed, err := gitOps.ChangedFiles(); err == nil {
+		state.ChangedFiles = changed
+		state.Dirty = changed > 0
+	}
+
+	if !state.Detached {
+		remote, branch, ok := gitOps.Upstream(state.Head)
+		if !ok {
+			// 没有配置上游分支时与主远程仓库的同名分支比较
+			if primary, found := config.PrimaryRemote(); found {
+				remote, branch = primary.Name, state.Head
+			}
+		}
+		if remote != "" {
+			if ahead, behind, err := gitOps.AheadBehindUpstream(state.Head, remote, branch); err == nil {
+				state.Upstream = remote + "/" + branch
+				state.Ahead, state.Behind = ahead, behind
+			}
+		}
+	}
+
+	if tag, since, err := gitOps.DescribeHead(config.TagPrefix, packageDir(config)); err == nil {
+		state.LatestTag, state.CommitsSinceTag = tag, since
+	}
+	return state
+}
+
+// nextReleaseVersion 计算下一次升级 patch 得到的版本号和标签，
+// 以最近的标签为基准，没有标签时使用配置中的版本
+func nextReleaseVersion(config *Config, latestTag string) (string, string) {
+	base := config.Version
+	if latestTag != "" {
+		base = strings.TrimPrefix(latestTag, config.TagPrefix)
+	}
+	current, err := parseSemVersion(base)
+	if err != nil {
+		return "", ""
+	}
+	next, _ := current.bump("patch")
+	return next.String(), releaseTagName(config, next.String())
+}
+
+// compareRepoLock 比较 .repo.lock 与配置中的仓库、分支和版本
+func compareRepoLock(config *Config) lockState {
+	if !fileExists(RepoLockFile) {
+		return lockState{State: lockMissing}
+	}
+	lock, err := readRepoLock()
+	if err != nil {
+		return lockState{State: lockCorrupt, Error: err.Error()}
+	}
+
+	var drift []lockFieldDrift
+	if primary, ok := config.PrimaryRemote(); ok && !sameRepoURL(lock.Repo, primary.URL) {
+		drift = append(drift, lockFieldDrift{Field: "repo", Lock: lock.Repo, Config: primary.URL})
+	}
+	if lock.Branch != config.Branch {
+		drift = append(drift, lockFieldDrift{Field: "branch", Lock: lock.Branch, Config: config.Branch})
+	}
+	if lock.CurrentVersion != "" && releaseTagName(config, lock.CurrentVersion) != releaseTagName(config, config.Version) {
+		drift = append(drift, lockFieldDrift{Field: "current_version", Lock: lock.CurrentVersion, Config: config.Version})
+	}
+	if len(drift) > 0 {
+		return lockState{State: lockDrift, Drift: drift}
+	}
+	return lockState{State: lockOK}
+}
+
+// compareRemote 比较主远程仓库在 git 中的地址与 Config.Repo
+func compareRemote(gitOps *GitOperations, config *Config) *remoteState {
+	if config.Repo == "" {
+		return nil
+	}
+	name := "origin"
+	if primary, ok := config.PrimaryRemote(); ok {
+		name = primary.Name
+	}
+	state := &remoteState{Name: name, Config: config.Repo}
+	if url, err := gitOps.GetRemoteURL(name); err == nil {
+		state.URL = url
+		state.Matches = sameRepoURL(url, config.Repo)
+	}
+	return state
+}
+
+// collectPublishState 判断是否有尚未发布的内容：配置中的版本还没有标签、
+// 最近的标签之后有新的提交，或者上一次发布没有完成
+func collectPublishState(gitOps *GitOperations, config *Config, git *gitState) *publishState {
+	state := &publishState{
+		VersionTagged:     config.Version != "" && gitOps.TagExists(releaseTagName(config, config.Version)),
+		UnreleasedCommits: git.CommitsSinceTag,
+	}
+	if git.LatestTag == "" {
+		// 还没有发布过，所有提交都未发布
+		state.UnreleasedCommits = 0
+		if commits, err := gitOps.CountCommits(); err == nil {
+			state.UnreleasedCommits = commits
+		}
+	}
+	if lock, err := readRepoLock(); err == nil && len(lock.History) > 0 {
+		last := lock.History[len(lock.History)-1]
+		state.LastVersion, state.LastOutcome = last.Version, last.Outcome
+	}
+	state.Pending = !state.VersionTagged || state.UnreleasedCommits > 0 ||
+		(state.LastOutcome != "" && state.LastOutcome != ReleaseSucceeded)
+	return state
+}
+
+// collectRemoteStatus 收集每个远程仓库的地址以及当前分支领先/落后的提交数
+func collectRemoteStatus(gitOps *GitOperations, config *Config, git *gitState) []remoteStatus {
+	branch := git.Head
+	if git.Detached {
+		branch = config.Branch
+	}
+
+	var remotes []remoteStatus
+	for _, remote := range config.ConfiguredRemotes() {
+		status := remoteStatus{Name: remote.Name, Role: remote.Role, URL: remote.URL}
+		if actual, err := gitOps.GetRemoteURL(remote.Name); err == nil {
+			status.ActualURL = actual
+		}
+		if ahead, behind, err := gitOps.AheadBehind(remote.Name, branch); err == nil {
+			status.Tracked, status.Ahead, status.Behind = true, ahead, behind
+		}
+		remotes = append(remotes, status)
+	}
+	return remotes
+}
+
+// printStatus 以文本输出状态
+func printStatus(report *statusReport) {
+	if report.Profile != "" {
+		fmt.Printf("profile: %s\n", report.Profile)
+	}
+	if report.Repo == "" {
+		fmt.Println(msg("status.not_bound"))
+	} else {
+		fmt.Printf("repo: %s\n", report.Repo)
+	}
+	fmt.Printf("branch: %s\n", report.Branch)
+	fmt.Printf("version: %s\n", report.Version)
+	fmt.Printf("tag_prefix: %s\n", report.TagPrefix)
+	fmt.Printf("auto_push: %t\n", report.AutoPush)
+	fmt.Printf("build_command: %s\n", report.BuildCommand)
+	if report.PreBuildError != "" {
+		fmt.Println(msg("status.prebuild_invalid", report.PreBuildError))
+	} else if report.PreBuildSteps > 0 {
+		fmt.Println(msg("status.prebuild_steps", report.PreBuildSteps))
+	}
+	if report.Release != nil {
+		fmt.Printf("release: prerelease=%t draft=%t assets=%s\n", report.Release.Prerelease, report.Release.Draft, strings.Join(report.Release.Assets, ", "))
+	}
+
+	if git := report.Git; git != nil {
+		fmt.Println("")
+		if git.Detached {
+			fmt.Println(msg("status.head_detached", git.Head))
+		} else {
+			fmt.Printf("head: %s\n", git.Head)
+		}
+		if git.Dirty {
+			fmt.Println(msg("status.worktree_dirty", git.ChangedFiles))
+		} else {
+			fmt.Println(msg("status.worktree_clean"))
+		}
+		if git.Upstream != "" {
+			fmt.Println(msg("status.upstream", git.Upstream, git.Ahead, git.Behind))
+		} else if !git.Detached {
+			fmt.Println(msg("status.upstream_none"))
+		}
+		if git.LatestTag != "" {
+			fmt.Println(msg("status.latest_tag", git.LatestTag, git.CommitsSinceTag))
+		} else {
+			fmt.Println(msg("status.latest_tag_none"))
+		}
+		if report.NextVersion != "" {
+			fmt.Println(msg("status.next_version", report.NextVersion, report.NextTag))
+		}
+	}
+
+	switch report.Lock.State {
+	case lockOK:
+		fmt.Println(msg("status.lock_ok", RepoLockFile))
+	case lockMissing:
+		fmt.Println(msg("status.lock_missing", RepoLockFile))
+	case lockCorrupt:
+		fmt.Printf("lock: ⚠️ %s\n", report.Lock.Error)
+	case lockDrift:
+		fmt.Println(msg("status.lock_drift", RepoLockFile))
+		for _, drift := range report.Lock.Drift {
+			fmt.Println(msg("status.lock_drift_field", drift.Field, RepoLockFile, drift.Lock, drift.Config))
+		}
+	}
+
+	if remote := report.Remote; remote != nil {
+		switch {
+		case remote.URL == "":
+			fmt.Println(msg("status.remote_missing", remote.Name))
+		case remote.Matches:
+			fmt.Println(msg("status.remote_ok", remote.Name))
+		default:
+			fmt.Println(msg("status.remote_mismatch", remote.Name, remote.URL, remote.Config))
+		}
+	}
+
+	if publish := report.Publish; publish != nil {
+		if !publish.Pending {
+			fmt.Println(msg("status.publish_none"))
+		} else {
+			var reasons []string
+			if !publish.VersionTagged {
+				reasons = append(reasons, msg("status.publish_version", report.Version))
+			}
+			if publish.UnreleasedCommits > 0 {
+				reasons = append(reasons, msg("status.publish_commits", publish.UnreleasedCommits))
+			}
+			if publish.LastOutcome != "" && publish.LastOutcome != ReleaseSucceeded {
+				reasons = append(reasons, msg("status.publish_incomplete", publish.LastVersion, publish.LastOutcome))
+			}
+			fmt.Println(msg("status.publish_pending", strings.Join(reasons, msg("status.reason_separator"))))
+		}
+	}
+
+	if git := report.Git; git != nil {
+		branch := git.Head
+		if git.Detached {
+			branch = report.Branch
+		}
+		printRemoteStatus(report.Remotes, branch)
+	}
+}
+
+// printRemoteStatus 输出每个远程仓库的地址以及当前分支领先/落后的提交数
+func printRemoteStatus(remotes []remoteStatus, branch string) {
+	if len(remotes) == 0 {
+		return
+	}
+
+	fmt.Println("remotes:")
+	for _, remote := range remotes {
+		url := remote.URL
+		if remote.ActualURL == "" {
+			url += msg("status.remote_not_added")
+		} else if !sameRepoURL(remote.ActualURL, remote.URL) {
+			url = msg("status.remote_differs", remote.ActualURL, remote.URL)
+		}
+
+		tracking := ""
+		if !remote.Tracked {
+			tracking = msg("status.untracked")
+		} else if remote.Ahead == 0 && remote.Behind == 0 {
+			tracking = msg("status.in_sync")
+		} else {
+			tracking = msg("status.ahead_behind", remote.Ahead, remote.Behind)
+		}
+
+		fmt.Printf("  %s [%s] %s (%s/%s: %s)\n", remote.Name, remote.Role, url, remote.Name, branch, tracking)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semVersion 语义化版本号，Prefix 保留版本号前的 v
+type semVersion struct {
+	Prefix     string
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+var semVersionPattern = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// parseSemVersion 解析 1.2.3、v1.2.3 或 1.2.3-rc.1 形式的版本号
+func parseSemVersion(version string) (semVersion, error) {
+	m := semVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if m == nil {
+		return semVersion{}, fmt.Errorf(msg("version.invalid"), version)
+	}
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+	return semVersion{Prefix: m[1], Major: major, Minor: minor, Patch: patch, Prerelease: m[5]}, nil
+}
+
+func (v semVersion) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// bump 按 major、minor 或 patch 升级版本号；预发布版本升级 patch 时得到对应的正式版本
+func (v semVersion) bump(part string) (semVersion, error) {
+	next := semVersion{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch part {
+	case "major":
+		next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+	case "minor":
+		next.Minor, next.Patch = v.Minor+1, 0
+	case "patch":
+		if v.Prerelease == "" {
+			next.Patch++
+		}
+	default:
+		return v, fmt.Errorf(msg("version.invalid_part"), part)
+	}
+	return next, nil
+}